@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Backend is a byte level store used as the second tier of a tiered cache, usually slower
+// and shared between processes.
+type Backend interface {
+	// Get returns the value and its expiry time, which is zero if it never expires,
+	// or ErrNotFound if the key is missing or expired.
+	Get(key string) ([]byte, time.Time, error)
+	// Put stores the value for the given duration, durations of zero or less never expire.
+	Put(key string, value []byte, d time.Duration) error
+	// Delete removes the key, deleting a missing key is not an error.
+	Delete(key string) error
+	// Clean removes the expired entries, which are otherwise only dropped once their key is
+	// read or written again. It should be called periodically.
+	Clean() error
+}
+
+type memoryEntry struct {
+	b []byte
+	t time.Time
+}
+
+type memoryBackend struct {
+	mp    map[string]memoryEntry
+	clock Clock
+
+	mu sync.Mutex
+}
+
+// NewMemoryBackend creates an in-memory backend, mostly useful as a reference and for tests.
+// Only the WithClock option is used.
+func NewMemoryBackend(opts ...Option) Backend {
+	return &memoryBackend{
+		mp:    make(map[string]memoryEntry),
+		clock: buildOptions(opts).clock,
+	}
+}
+
+func (m *memoryBackend) Get(key string) ([]byte, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.mp[key]
+	if !ok {
+		return nil, time.Time{}, ErrNotFound
+	}
+
+	if !e.t.IsZero() && e.t.Before(m.clock.Now()) {
+		delete(m.mp, key)
+		return nil, time.Time{}, ErrNotFound
+	}
+
+	return append([]byte(nil), e.b...), e.t, nil
+}
+
+func (m *memoryBackend) Put(key string, value []byte, d time.Duration) error {
+	var e = memoryEntry{
+		b: append([]byte(nil), value...),
+	}
+
+	if d > 0 {
+		e.t = m.clock.Now().Add(d)
+	}
+
+	m.mu.Lock()
+	m.mp[key] = e
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *memoryBackend) Delete(key string) error {
+	m.mu.Lock()
+	delete(m.mp, key)
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *memoryBackend) Clean() error {
+	var now = m.clock.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, e := range m.mp {
+		if !e.t.IsZero() && e.t.Before(now) {
+			delete(m.mp, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Prefix of the files being written, renamed once complete
+const tmpPrefix = ".tmp-"
+
+type fileBackend struct {
+	dir   string
+	clock Clock
+}
+
+// NewFileBackend creates a backend storing one file per key in the given directory, which is
+// created if missing. Only the WithClock option is used.
+//
+// Each file holds the expiry time followed by the value, files are replaced atomically so
+// several processes may share the directory. Expired files are deleted when read, or by Clean
+// for keys that are not read again.
+func NewFileBackend(dir string, opts ...Option) (Backend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &fileBackend{
+		dir:   dir,
+		clock: buildOptions(opts).clock,
+	}, nil
+}
+
+// path returns the file used for the key, keys are hashed so any string is a valid key.
+func (f *fileBackend) path(key string) string {
+	var sum = sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:]))
+}
+
+// openEntry opens the file at the path and reads its expiry time, leaving the value to be read.
+// The file info tells the file apart from one written over it later.
+func openEntry(path string) (*os.File, fs.FileInfo, time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, time.Time{}, err
+	}
+
+	var b [8]byte
+	if _, err := io.ReadFull(file, b[:]); err != nil {
+		file.Close()
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrCorrupted
+		}
+		return nil, nil, time.Time{}, err
+	}
+
+	var t time.Time
+	if n := int64(binary.BigEndian.Uint64(b[:])); n != 0 {
+		t = time.Unix(0, n)
+	}
+
+	return file, fi, t, nil
+}
+
+// removeExpired deletes the expired file at the path unless another process replaced it since it
+// was opened. A fresh file renamed in right between the check and the removal is lost, which
+// only costs a miss.
+func removeExpired(path string, fi fs.FileInfo) {
+	if cur, err := os.Lstat(path); err == nil && os.SameFile(fi, cur) {
+		os.Remove(path)
+	}
+}
+
+func (f *fileBackend) Get(key string) ([]byte, time.Time, error) {
+	var path = f.path(key)
+
+	file, fi, t, err := openEntry(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, ErrNotFound
+	} else if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if !t.IsZero() && t.Before(f.clock.Now()) {
+		// Closed first, open files cannot be removed on every system
+		file.Close()
+		removeExpired(path, fi)
+		return nil, time.Time{}, ErrNotFound
+	}
+
+	b, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return b, t, nil
+}
+
+func (f *fileBackend) Put(key string, value []byte, d time.Duration) error {
+	var b = make([]byte, 8+len(value))
+
+	if d > 0 {
+		binary.BigEndian.PutUint64(b, uint64(f.clock.Now().Add(d).UnixNano()))
+	}
+	copy(b[8:], value)
+
+	tmp, err := os.CreateTemp(f.dir, tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path(key))
+}
+
+func (f *fileBackend) Delete(key string) error {
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (f *fileBackend) Clean() error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	var now = f.clock.Now()
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), tmpPrefix) {
+			continue
+		}
+
+		var path = filepath.Join(f.dir, e.Name())
+
+		file, fi, t, err := openEntry(path)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrCorrupted) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		file.Close()
+
+		if !t.IsZero() && t.Before(now) {
+			removeExpired(path, fi)
+		}
+	}
+
+	return nil
+}
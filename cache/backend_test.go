@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testBackend(t *testing.T, b Backend, clock *FakeClock) {
+	if _, _, err := b.Get("a"); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+
+	if err := b.Put("a", []byte("hello"), time.Minute); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := b.Put("b/../c", []byte("world"), NoExpiration); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if v, exp, err := b.Get("a"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	} else if !bytes.Equal(v, []byte("hello")) {
+		t.Errorf("Expected hello, got %q", v)
+	} else if !exp.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("Expected expiry %v, got %v", clock.Now().Add(time.Minute), exp)
+	}
+
+	clock.Advance(time.Hour)
+
+	if _, _, err := b.Get("a"); err != ErrNotFound {
+		t.Errorf("Expected error %v after expiry, got %v", ErrNotFound, err)
+	}
+
+	if v, exp, err := b.Get("b/../c"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	} else if !bytes.Equal(v, []byte("world")) {
+		t.Errorf("Expected world, got %q", v)
+	} else if !exp.IsZero() {
+		t.Errorf("Expected no expiry, got %v", exp)
+	}
+
+	if err := b.Delete("b/../c"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if _, _, err := b.Get("b/../c"); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+
+	if err := b.Delete("missing"); err != nil {
+		t.Errorf("Expected no error deleting a missing key, got %v", err)
+	}
+}
+
+func TestMemoryBackend(t *testing.T) {
+	t.Parallel()
+
+	var clock = NewFakeClock(time.Now())
+
+	testBackend(t, NewMemoryBackend(WithClock(clock)), clock)
+}
+
+func TestFileBackend(t *testing.T) {
+	t.Parallel()
+
+	var clock = NewFakeClock(time.Now())
+
+	b, err := NewFileBackend(filepath.Join(t.TempDir(), "cache"), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	testBackend(t, b, clock)
+}
+
+func TestFileBackendCorrupted(t *testing.T) {
+	t.Parallel()
+
+	var dir = t.TempDir()
+
+	b, err := NewFileBackend(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	os.WriteFile(b.(*fileBackend).path("a"), []byte{1, 2}, 0o644)
+
+	if _, _, err := b.Get("a"); err != ErrCorrupted {
+		t.Errorf("Expected error %v, got %v", ErrCorrupted, err)
+	}
+}
+
+func TestBackendClean(t *testing.T) {
+	t.Parallel()
+
+	var clock = NewFakeClock(time.Now())
+
+	f, err := NewFileBackend(t.TempDir(), WithClock(clock))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var (
+		m = NewMemoryBackend(WithClock(clock))
+
+		files = func() int {
+			entries, _ := os.ReadDir(f.(*fileBackend).dir)
+			return len(entries)
+		}
+		entries = func() int {
+			var mb = m.(*memoryBackend)
+
+			mb.mu.Lock()
+			defer mb.mu.Unlock()
+			return len(mb.mp)
+		}
+	)
+
+	for _, b := range []Backend{f, m} {
+		b.Put("a", []byte("a"), time.Minute)
+		b.Put("b", []byte("b"), time.Hour)
+		b.Put("c", []byte("c"), NoExpiration)
+	}
+
+	clock.Advance(30 * time.Minute)
+
+	// Expired entries that are never read again are only dropped by Clean
+	for _, b := range []Backend{f, m} {
+		if err := b.Clean(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+
+	if nf, ne := files(), entries(); nf != 2 || ne != 2 {
+		t.Errorf("Expected 2 files and 2 entries left, got %d and %d", nf, ne)
+	}
+
+	// Reading an expired file deletes it
+	clock.Advance(time.Hour)
+
+	if _, _, err := f.Get("b"); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+
+	if n := files(); n != 1 {
+		t.Errorf("Expected 1 file left, got %d", n)
+	}
+}
@@ -1,94 +1,674 @@
 package cache
 
 import (
+	"container/list"
+	"context"
+	"errors"
+	"hash/maphash"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	// NoExpiration can be passed as the duration of an entry that should never expire.
+	NoExpiration time.Duration = -1
+	// DefaultExpiration can be passed as the duration of an entry to use the cache default,
+	// see WithDefaultExpiration. Caches without a default treat it as NoExpiration.
+	DefaultExpiration time.Duration = 0
+	// KeepExpiration can be passed to Update to keep the expiry time of an existing entry,
+	// new entries use the default expiration.
+	KeepExpiration time.Duration = -2
+)
+
+// UpdateAction is what Compute does with the entry once its function returns.
+type UpdateAction int
+
+const (
+	// UpdateStore stores the returned value.
+	UpdateStore UpdateAction = iota
+	// UpdateDelete removes the entry.
+	UpdateDelete
+	// UpdateKeep leaves the entry as it is, without firing callbacks or events.
+	UpdateKeep
+)
+
+var (
+	ErrClosed       = errors.New("cache closed")
+	ErrLoaderPanics = errors.New("cache loader panicked")
+
+	ErrSnapshotVersion = errors.New("unsupported cache snapshot version")
+
+	ErrNotFound = errors.New("cache entry not found")
+	ErrCached   = errors.New("cached error")
+
+	ErrCorrupted = errors.New("cache backend entry corrupted")
+)
+
 type Cache[I comparable, T any] interface {
 	Put(time.Duration, I, T)
+	// Set adds an entry with the default expiration, same as Put(DefaultExpiration, ...).
+	Set(I, T)
 	Get(I) (T, bool)
+	// GetWithExpiry is Get also returning the expiry time of the entry, zero if it never expires.
+	GetWithExpiry(I) (T, time.Time, bool)
+	// GetEntry returns the value of a live entry along with its metadata, without counting as a read.
+	//
+	//   e, ok := cache.GetEntry(key)
+	//   if ok && time.Until(e.Expires) < time.Minute { ... }
+	GetEntry(I) (Entry[T], bool)
 	Remove(I) (T, bool)
 	Clean()
+
+	// GetMany looks up the keys with one lock acquisition per shard, returning the live values found
+	// and the keys that were missed, in the order given. Entries are refreshed, renewed and counted
+	// in the stats as with Get.
+	GetMany([]I) (map[I]T, []I)
+	// PutMany adds the entries with the same duration, locking each shard once.
+	PutMany(time.Duration, map[I]T)
+	// PutManyTimed adds the entries with their own durations, locking each shard once.
+	//
+	//   cache.PutManyTimed(map[string]cache.Timed[int]{"a": {Value: 1, Duration: time.Minute}})
+	PutManyTimed(map[I]Timed[T])
+	// RemoveMany removes the keys, locking each shard once, and returns how many live entries were removed.
+	RemoveMany([]I) int
+
+	// Update atomically replaces the entry with the value returned by the function, which receives the
+	// current value and whether there is one. Returning false removes the entry instead. The new value
+	// is stored as with Put for the given duration, or only replaces the value with KeepExpiration.
+	// The function runs with the cache locked and must not use the cache.
+	//
+	//   cache.Update(key, time.Minute, func(n int, ok bool) (int, bool) { return n + 1, true })
+	Update(I, time.Duration, func(T, bool) (T, bool)) (T, bool)
+	// Compute is Update with the function choosing to store, delete or keep the entry as it is, it
+	// returns the value left in the cache and whether there is one.
+	//
+	//   cache.Compute(key, KeepExpiration, func(s string, ok bool) (string, cache.UpdateAction) {
+	//       if !ok { return "", cache.UpdateKeep }
+	//       return s + "!", cache.UpdateStore
+	//   })
+	Compute(I, time.Duration, func(T, bool) (T, UpdateAction)) (T, bool)
+	// CompareAndSwap replaces the value of a live entry if it equals old, keeping its expiry time.
+	// It panics if the values are not comparable.
+	CompareAndSwap(key I, old, new T) bool
+	// PutIfAbsent adds the entry only if the key has no live entry, it returns the current value
+	// and true if there was one, or the given value and false if it was added.
+	PutIfAbsent(time.Duration, I, T) (T, bool)
+	// GetAndDelete removes the entry and returns its value if it was live, same as Remove.
+	GetAndDelete(I) (T, bool)
+
+	// PutError caches an error for the key, usually with a shorter duration than values.
+	// Get treats the entry as a miss, GetErr and GetOrLoad return the error wrapped with ErrCached.
+	PutError(time.Duration, I, error)
+	// GetErr returns the cached value, the cached error wrapped with ErrCached, or ErrNotFound.
+	//
+	//   v, err := cache.GetErr(key)
+	//   if errors.Is(err, cache.ErrCached) { ... }
+	GetErr(I) (T, error)
+
+	// PutRefresh adds an entry that goes stale after the soft duration and expires after the hard one.
+	// Reading a stale entry returns it as usual and reloads it in the background with the loader
+	// set by SetLoader, a failed reload keeps the stale value until it expires.
+	PutRefresh(soft, hard time.Duration, key I, value T)
+	// SetLoader sets the loader used to refresh stale entries, nil stops refreshing them.
+	//
+	//   cache.SetLoader(func(id string) (User, error) { return db.User(id) })
+	SetLoader(func(I) (T, error))
+
+	// PutTagged adds an entry that can be removed along with every other entry sharing one of its tags.
+	//
+	//   cache.PutTagged(time.Minute, key, view, "tenant:"+tenantID)
+	//   cache.InvalidateTag("tenant:" + tenantID)
+	PutTagged(time.Duration, I, T, ...string)
+	// InvalidateTag removes every entry carrying the tag and returns how many were removed.
+	InvalidateTag(string) int
+
+	// PutSliding adds an entry whose duration starts over every time it is read with Get.
+	PutSliding(time.Duration, I, T)
+	// Touch starts the duration of a live entry over, as if it was just added.
+	// Returns false if the entry is missing or already expired.
+	Touch(I) bool
+	// SetExpiration gives a live entry a new duration starting now, keeping its value, tags and metadata.
+	// Returns false if the entry is missing or already expired.
+	SetExpiration(I, time.Duration) bool
+
+	// GetOrLoad returns the cached value or calls the loader and caches its result for the given duration.
+	// Concurrent calls for the same key share a single loader call, errors are returned but not cached
+	// unless WithErrorExpiration is used.
+	//
+	//   v, err := cache.GetOrLoad(key, time.Minute, func(key string) (User, error) { ... })
+	GetOrLoad(I, time.Duration, func(I) (T, error)) (T, error)
+	// GetOrLoadContext is GetOrLoad for loaders taking a context. The loader runs with the values of the
+	// first caller's context but is only canceled once every caller waiting on it gave up, each caller
+	// returns as soon as its own context is done. Errors of a canceled loader are not cached, and a panic
+	// in the loader is raised again in the callers waiting on it.
+	//
+	//   v, err := cache.GetOrLoadContext(ctx, key, time.Minute, func(ctx context.Context, key string) (User, error) { ... })
+	GetOrLoadContext(context.Context, I, time.Duration, func(context.Context, I) (T, error)) (T, error)
+
+	// Watch subscribes to the events of the key, and WatchAll to those of every key. Each subscription
+	// buffers its events, see WithWatchBuffer, and drops the oldest ones once a slow consumer lets the
+	// buffer fill up, counting them in Dropped. Close must be called to release the subscription.
+	//
+	//   sub := cache.Watch("config")
+	//   defer sub.Close()
+	//   for ev := range sub.Events() { ... }
+	Watch(I) Subscription[I, T]
+	WatchAll() Subscription[I, T]
+
+	// OnEvict registers a function to be called whenever an entry leaves the cache or has its value replaced.
+	// Callbacks run after the cache lock is released, so they may use the cache themselves.
+	//
+	//   cache.OnEvict(func(key string, conn net.Conn, reason cache.EvictReason) { conn.Close() })
+	OnEvict(func(I, T, EvictReason))
+
+	// Stats returns a snapshot of the cache counters.
+	Stats() Stats
+	// ResetStats sets all the cache counters back to zero, Size is not affected.
+	ResetStats()
+
+	// Len returns the number of live entries.
+	Len() int
+	// Keys returns the keys of the live entries.
+	Keys() []I
+	// Items returns a copy of the live entries.
+	Items() map[I]T
+	// Range calls the function for every live entry with its expiry time, which is zero for entries
+	// that never expire, until it returns false. Entries are read beforehand so the function
+	// may use the cache, changes made meanwhile are not seen by Range.
+	Range(func(I, T, time.Time) bool)
+
+	// Save writes the live entries along with their expiry times to the writer, using the cache codec.
+	Save(io.Writer) error
+	// Load adds the entries written by Save to the cache, entries that expired in the meantime are skipped.
+	Load(io.Reader) error
+
+	// Close stops the background janitor, if any. The cache stays usable afterwards,
+	// expired entries are only dropped lazily or by calling Clean.
+	Close() error
 }
 
 type cacheItem[T any] struct {
 	t time.Time
 	v T
+
+	// Duration given when the entry was added, used to renew it
+	d       time.Duration
+	sliding bool
+
+	// Weight given by the cache weigher, zero without one
+	w int64
+
+	// Time after which the item is reloaded in the background, zero if never
+	r  time.Time
+	rd time.Duration
+
+	// Error cached in place of a value, see PutError
+	err error
+
+	tags []string
+
+	el *list.Element
+	// Position in the shard expiry heap, -1 when not in it
+	hi int
+
+	// When the value was stored
+	added time.Time
+
+	// Reads since the value was stored and the last one in unix nanoseconds, only kept
+	// with WithAccessTracking. Atomic as reads only hold the read lock.
+	hits     atomic.Uint64
+	accessed atomic.Int64
+}
+
+func (it *cacheItem[T]) stale(now time.Time) bool {
+	return !it.r.IsZero() && !now.Before(it.r)
+}
+
+func (it *cacheItem[T]) expired(now time.Time) bool {
+	return !it.t.IsZero() && it.t.Before(now)
+}
+
+// deadline returns the expiry time of an entry added at the given time, zero if it never expires.
+func deadline(now time.Time, d time.Duration) time.Time {
+	if d == NoExpiration {
+		return time.Time{}
+	}
+
+	return now.Add(d)
 }
 
 type cache[I comparable, T any] struct {
-	mp map[I]cacheItem[T]
+	shards []*shard[I, T]
+	seed   maphash.Seed
+
+	watchers *watchers[I, T]
+
+	// Duration used for DefaultExpiration, never DefaultExpiration itself
+	ttl time.Duration
+	// Duration used for errors returned by GetOrLoad loaders, zero to not cache them
+	errTTL time.Duration
+
+	codec Codec
+	clock Clock
+
+	// Loader used to refresh stale entries, see SetLoader
+	loader func(I) (T, error)
+
+	// Loads running for each key, lmu also guards the loader
+	calls map[I]*loadCall[T]
+	lmu   sync.Mutex
+
+	isClosed bool
+	chClose  chan struct{}
+	chDone   chan struct{}
+
+	mu sync.Mutex
+}
+
+// shard holds a subset of the keys behind its own lock, every cache has at least one.
+type shard[I comparable, T any] struct {
+	mp map[I]*cacheItem[T]
+
+	// Eviction policy used by bounded caches, nil for unbounded caches
+	pol      policy[I]
+	capacity int
+
+	// Entries with an expiry time, soonest first
+	exp expiryHeap[I, T]
+
+	// Keys carrying each tag, see PutTagged
+	tags map[string]map[I]struct{}
+
+	// Subscriptions shared by every shard, see Watch
+	watchers *watchers[I, T]
+
+	// Total weight of the entries and its limit, see NewWeighted
+	weigher   Weigher[I, T]
+	weight    int64
+	maxWeight int64
+
+	// Renew entries on every read, see WithSlidingExpiration
+	sliding bool
+
+	// Count the reads of each entry, see WithAccessTracking
+	tracking bool
+
+	onEvict []func(I, T, EvictReason)
+	evicted []eviction[I, T]
+
+	// Counters of the shard, summed by Stats
+	stats counters
+	clock Clock
 
 	mu sync.RWMutex
 }
 
-func New[I comparable, T any]() Cache[I, T] {
-	return &cache[I, T]{
-		mp: make(map[I]cacheItem[T]),
+// New creates an unbounded cache, entries are only dropped once they expire or are removed.
+func New[I comparable, T any](opts ...Option) Cache[I, T] {
+	return newCache[I, T](0, nil, 0, nil, opts)
+}
+
+// newCache creates a cache bounded by the capacity, the weight limit or neither. Bounded caches use
+// the policies created by newPolicy for the capacity of each shard, or LRU if it is nil.
+func newCache[I comparable, T any](capacity int, newPolicy func(int) policy[I], maxWeight int64, weigher Weigher[I, T], opts []Option) *cache[I, T] {
+	var o = buildOptions(opts)
+
+	if capacity > 0 && o.shards > capacity {
+		panic("expected a capacity of at least one entry per shard")
+	}
+
+	// A shard could not refuse only the values heavier than the whole limit without going over it
+	if maxWeight > 0 && o.shards > 1 {
+		panic("expected a single shard with a max weight")
+	}
+
+	var c = &cache[I, T]{
+		shards: make([]*shard[I, T], o.shards),
+		seed:   maphash.MakeSeed(),
+
+		watchers: newWatchers[I, T](o.watchBuffer),
+
+		ttl:    o.ttl,
+		errTTL: o.errTTL,
+
+		codec: o.codec,
+		clock: o.clock,
+
+		calls: make(map[I]*loadCall[T]),
+
+		chClose: make(chan struct{}),
 	}
+
+	for n := range c.shards {
+		var s = &shard[I, T]{
+			mp:   make(map[I]*cacheItem[T]),
+			tags: make(map[string]map[I]struct{}),
+
+			watchers: c.watchers,
+
+			sliding:  o.sliding,
+			tracking: o.tracking,
+
+			clock: o.clock,
+		}
+
+		if capacity > 0 || maxWeight > 0 {
+			s.capacity = int(split(int64(capacity), o.shards, n))
+
+			if newPolicy != nil {
+				s.pol = newPolicy(s.capacity)
+			} else {
+				s.pol = newLRUPolicy[I]()
+			}
+
+			s.weigher = weigher
+			s.maxWeight = maxWeight
+		}
+
+		c.shards[n] = s
+	}
+
+	if o.janitor > 0 {
+		c.chDone = make(chan struct{})
+		go c.janitor(o.janitor)
+	}
+
+	return c
 }
 
 func (c *cache[I, T]) Put(d time.Duration, i I, v T) {
+	var s = c.shard(i)
+	s.put(c.expiration(d), i, v, s.sliding)
+}
+
+func (c *cache[I, T]) Set(i I, v T) {
+	c.Put(DefaultExpiration, i, v)
+}
+
+func (c *cache[I, T]) Get(i I) (T, bool) {
+	v, err, ok := c.lookup(i)
+	if !ok || err != nil {
+		var zero T
+		return zero, false
+	}
+
+	return v, true
+}
+
+// lookup returns the cached value or error and refreshes stale entries.
+func (c *cache[I, T]) lookup(i I) (T, error, bool) {
+	v, err, _, ok, stale := c.shard(i).get(i)
+	if stale {
+		c.refresh(i)
+	}
+
+	return v, err, ok
+}
+
+func (c *cache[I, T]) Remove(i I) (T, bool) {
+	return c.shard(i).remove(i)
+}
+
+func (c *cache[I, T]) Clean() {
+	var now = c.clock.Now()
+
+	for _, s := range c.shards {
+		s.clean(now)
+	}
+}
+
+// expiration resolves DefaultExpiration to the duration configured for the cache.
+func (c *cache[I, T]) expiration(d time.Duration) time.Duration {
+	if d == DefaultExpiration {
+		return c.ttl
+	}
+
+	return d
+}
+
+func (c *cache[I, T]) Close() error {
 	c.mu.Lock()
-	c.mp[i] = cacheItem[T]{
-		t: time.Now().Add(d),
-		v: v,
+	if c.isClosed {
+		c.mu.Unlock()
+		return ErrClosed
 	}
+	c.isClosed = true
+	close(c.chClose)
 	c.mu.Unlock()
+
+	if c.chDone != nil {
+		<-c.chDone
+	}
+
+	return nil
 }
 
-func (c *cache[I, T]) Get(i I) (T, bool) {
-	var zero T
+func (c *cache[I, T]) janitor(interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+	defer close(c.chDone)
+
+	for {
+		select {
+		case <-c.chClose:
+			return
+
+		case <-ticker.C:
+			c.Clean()
+		}
+	}
+}
+
+func (s *shard[I, T]) put(d time.Duration, i I, v T, sliding bool) {
+	var now = s.clock.Now()
+
+	s.mu.Lock()
+	s.store(now, deadline(now, d), d, i, v, sliding)
+	s.unlock()
+}
+
+// store adds or replaces the entry with the given expiry time and returns it, or nil if the value
+// was refused, must be called with the lock held.
+func (s *shard[I, T]) store(now, t time.Time, d time.Duration, i I, v T, sliding bool) *cacheItem[T] {
+	var it = s.insert(now, t, d, i, v, sliding)
+	if it != nil {
+		s.notify(EventPut, i, v)
+	}
+
+	return it
+}
+
+// insert is store without the put event, for entries that do not hold a value,
+// must be called with the lock held.
+func (s *shard[I, T]) insert(now, t time.Time, d time.Duration, i I, v T, sliding bool) *cacheItem[T] {
+	var w = s.weigh(i, v)
+
+	if s.maxWeight > 0 && w > s.maxWeight {
+		// Drop the old value as well, it would be stale otherwise
+		if it, ok := s.mp[i]; ok {
+			if old, live := s.live(i, now); live {
+				s.notify(EventRemove, i, old)
+			}
+			s.delete(i, it, EvictReplaced)
+		}
+
+		s.stats.rejections.Add(1)
+		return nil
+	}
+
+	if it, ok := s.mp[i]; ok {
+		s.evict(i, it, replaceReason(it, now))
+		it.t = t
+		it.v = v
+		it.d = d
+		it.added = now
+		it.hits.Store(0)
+		it.accessed.Store(0)
+		s.schedule(i, it)
+		it.sliding = sliding
+		it.r, it.rd = time.Time{}, 0
+		it.err = nil
+		s.untag(i, it)
+
+		s.weight += w - it.w
+		it.w = w
+
+		s.touch(it)
+		s.trim()
+		return s.kept(i, it)
+	}
+
+	var it = &cacheItem[T]{
+		t: t,
+		v: v,
+
+		d:       d,
+		sliding: sliding,
+
+		w: w,
+
+		hi: -1,
 
-	c.mu.RLock()
-	v, ok := c.mp[i]
-	c.mu.RUnlock()
+		added: now,
+	}
+	s.mp[i] = it
+	s.schedule(i, it)
+	s.weight += w
+	s.track(i, it)
+	return s.kept(i, it)
+}
+
+// kept returns the item if it is still cached after the policy made room for it, or nil,
+// must be called with the lock held.
+func (s *shard[I, T]) kept(i I, it *cacheItem[T]) *cacheItem[T] {
+	if s.mp[i] != it {
+		return nil
+	}
+
+	return it
+}
 
+// get looks up the key, returning the value or error, the expiry time, whether it was found
+// and whether it is stale and should be refreshed.
+func (s *shard[I, T]) get(i I) (T, error, time.Time, bool, bool) {
+	if s.pol != nil {
+		return s.getLRU(i)
+	}
+
+	var (
+		zero T
+		now  = s.clock.Now()
+	)
+
+	s.mu.RLock()
+	it, ok := s.mp[i]
 	if !ok {
-		return zero, false
+		s.mu.RUnlock()
+
+		s.stats.misses.Add(1)
+		return zero, nil, time.Time{}, false, false
 	}
 
-	if v.t.Before(time.Now()) {
-		c.mu.Lock()
-		delete(c.mp, i)
-		c.mu.Unlock()
+	v, err, t, expired, sliding, stale := it.v, it.err, it.t, it.expired(now), it.sliding, it.stale(now)
+	if !expired {
+		s.access(it, now)
+	}
+	s.mu.RUnlock()
 
-		return zero, false
+	if expired {
+		s.mu.Lock()
+		if it, ok := s.mp[i]; ok && it.expired(now) {
+			s.delete(i, it, EvictExpired)
+		}
+		s.unlock()
+
+		s.stats.misses.Add(1)
+		return zero, nil, time.Time{}, false, false
 	}
 
-	return v.v, true
+	if sliding {
+		s.mu.Lock()
+		if cur, ok := s.mp[i]; ok && cur == it {
+			s.renew(i, it, now)
+			t = it.t
+		}
+		s.mu.Unlock()
+	}
+
+	s.hit(err)
+	return v, err, t, true, stale
 }
 
-func (c *cache[I, T]) Remove(i I) (T, bool) {
+// peek looks up the key without counting it as a hit or miss and without dropping it once expired.
+func (s *shard[I, T]) peek(i I) (T, error, bool) {
 	var zero T
 
-	c.mu.Lock()
-	v, ok := c.mp[i]
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok := s.mp[i]
+	if !ok || it.expired(s.clock.Now()) {
+		return zero, nil, false
+	}
+
+	return it.v, it.err, true
+}
+
+func (s *shard[I, T]) remove(i I) (T, bool) {
+	var (
+		zero T
+		now  = s.clock.Now()
+	)
+
+	s.mu.Lock()
+	v, ok := s.mp[i]
 	if ok {
-		delete(c.mp, i)
+		s.delete(i, v, removeReason(v, now))
 	}
-	c.mu.Unlock()
+	s.unlock()
 
 	if !ok {
 		return zero, false
 	}
 
-	if v.t.Before(time.Now()) {
+	if v.expired(now) || v.err != nil {
 		return zero, false
 	}
 
 	return v.v, true
 }
 
-func (c *cache[I, T]) Clean() {
-	var now = time.Now()
+// clean removes the expired entries, only visiting those thanks to the expiry heap.
+func (s *shard[I, T]) clean(now time.Time) {
+	s.mu.Lock()
+	for len(s.exp) > 0 && s.exp[0].it.expired(now) {
+		s.delete(s.exp[0].k, s.exp[0].it, EvictExpired)
+	}
+	s.unlock()
+}
 
-	c.mu.Lock()
-	for k, v := range c.mp {
-		if v.t.Before(now) {
-			delete(c.mp, k)
-		}
+// delete removes the item from the map and the recency list, must be called with the lock held.
+func (s *shard[I, T]) delete(i I, it *cacheItem[T], reason EvictReason) {
+	delete(s.mp, i)
+	s.weight -= it.w
+	s.untag(i, it)
+	s.unschedule(it)
+
+	if it.el != nil {
+		s.pol.remove(it.el)
+		it.el = nil
 	}
-	c.mu.Unlock()
+
+	switch reason {
+	case EvictExpired:
+		s.stats.expirations.Add(1)
+	case EvictRemoved:
+		s.stats.removals.Add(1)
+	case EvictCapacity:
+		s.stats.evictions.Add(1)
+	}
+
+	s.evict(i, it, reason)
 }
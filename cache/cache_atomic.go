@@ -0,0 +1,144 @@
+package cache
+
+import "time"
+
+func (c *cache[I, T]) Update(i I, d time.Duration, fn func(T, bool) (T, bool)) (T, bool) {
+	return c.Compute(i, d, func(v T, ok bool) (T, UpdateAction) {
+		if v, store := fn(v, ok); store {
+			return v, UpdateStore
+		}
+		return v, UpdateDelete
+	})
+}
+
+func (c *cache[I, T]) Compute(i I, d time.Duration, fn func(T, bool) (T, UpdateAction)) (T, bool) {
+	var (
+		s    = c.shard(i)
+		now  = c.clock.Now()
+		zero T
+	)
+
+	var keep = d == KeepExpiration
+	if keep {
+		d = DefaultExpiration
+	}
+	d = c.expiration(d)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	var old, ok = s.live(i, now)
+
+	v, action := fn(old, ok)
+	switch action {
+	case UpdateKeep:
+		return old, ok
+
+	case UpdateDelete:
+		if it, exists := s.mp[i]; exists {
+			s.delete(i, it, removeReason(it, now))
+		}
+		return zero, false
+	}
+
+	if keep && ok {
+		if !s.swap(i, s.mp[i], v) {
+			return zero, false
+		}
+		return v, true
+	}
+
+	var sliding = s.sliding
+	if it, exists := s.mp[i]; exists && ok {
+		sliding = it.sliding
+	}
+
+	if s.store(now, deadline(now, d), d, i, v, sliding) == nil {
+		return zero, false
+	}
+
+	return v, true
+}
+
+func (c *cache[I, T]) CompareAndSwap(i I, old, new T) bool {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+	)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	cur, ok := s.live(i, now)
+	if !ok || any(cur) != any(old) {
+		return false
+	}
+
+	return s.swap(i, s.mp[i], new)
+}
+
+func (c *cache[I, T]) PutIfAbsent(d time.Duration, i I, v T) (T, bool) {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+	)
+
+	d = c.expiration(d)
+
+	s.mu.Lock()
+	defer s.unlock()
+
+	if cur, ok := s.live(i, now); ok {
+		return cur, true
+	}
+
+	s.store(now, deadline(now, d), d, i, v, s.sliding)
+	return v, false
+}
+
+func (c *cache[I, T]) GetAndDelete(i I) (T, bool) {
+	return c.Remove(i)
+}
+
+// live returns the value of the entry if it is neither expired nor a cached error,
+// must be called with the lock held.
+func (s *shard[I, T]) live(i I, now time.Time) (T, bool) {
+	var zero T
+
+	it, ok := s.mp[i]
+	if !ok || it.expired(now) || it.err != nil {
+		return zero, false
+	}
+
+	return it.v, true
+}
+
+// swap replaces only the value of the item, keeping its expiry time and tags, and returns
+// false if the new value was refused for its weight or evicted to make room for it, must be
+// called with the lock held.
+func (s *shard[I, T]) swap(i I, it *cacheItem[T], v T) bool {
+	var w = s.weigh(i, v)
+
+	if s.maxWeight > 0 && w > s.maxWeight {
+		s.notify(EventRemove, i, it.v)
+		s.delete(i, it, EvictReplaced)
+		s.stats.rejections.Add(1)
+		return false
+	}
+
+	s.evict(i, it, EvictReplaced)
+	it.v = v
+
+	s.weight += w - it.w
+	it.w = w
+
+	s.touch(it)
+	s.trim()
+
+	if s.kept(i, it) == nil {
+		return false
+	}
+
+	s.notify(EventPut, i, v)
+	return true
+}
@@ -0,0 +1,271 @@
+package cache
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c  = New[string, int](WithShards(4))
+		wg sync.WaitGroup
+	)
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				c.Update("counter", time.Minute, func(n int, ok bool) (int, bool) {
+					return n + 1, true
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if v, _ := c.Get("counter"); v != 8000 {
+		t.Errorf("Expected 8000, got %d", v)
+	}
+
+	// Returning false removes the entry
+	if _, ok := c.Update("counter", time.Minute, func(n int, ok bool) (int, bool) { return 0, false }); ok {
+		t.Error("Expected false, got true")
+	}
+
+	if _, ok := c.Get("counter"); ok {
+		t.Error("Expected entry to be removed")
+	}
+}
+
+func TestUpdateMissing(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, []int](WithClock(clock))
+
+		seen []bool
+	)
+
+	var appendOne = func(v []int, ok bool) ([]int, bool) {
+		seen = append(seen, ok)
+		return append(v, 1), true
+	}
+
+	c.Update(0, time.Second, appendOne)
+	c.Update(0, time.Second, appendOne)
+
+	clock.Advance(time.Minute)
+	c.Update(0, time.Second, appendOne)
+
+	c.PutError(time.Minute, 1, io.EOF)
+	c.Update(1, time.Second, appendOne)
+
+	if expect := []bool{false, true, false, false}; !reflect.DeepEqual(seen, expect) {
+		t.Errorf("Expected %v, got %v", expect, seen)
+	}
+
+	if v, _ := c.Get(0); !reflect.DeepEqual(v, []int{1}) {
+		t.Errorf("Expected expired value to be started over, got %v", v)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c       = New[int, int]()
+		sub     = c.Watch(0)
+		evicted int
+	)
+	defer sub.Close()
+
+	c.OnEvict(func(int, int, EvictReason) {
+		evicted++
+	})
+
+	c.PutTagged(time.Minute, 0, 1, "x")
+
+	var keep = func(n int, ok bool) (int, UpdateAction) {
+		return n + 1, UpdateKeep
+	}
+
+	if v, ok := c.Compute(0, time.Second, keep); !ok || v != 1 {
+		t.Errorf("Expected 1 to be kept, got %d, %v", v, ok)
+	}
+
+	if _, ok := c.Compute(1, time.Second, keep); ok {
+		t.Error("Expected missing entry to stay missing")
+	}
+
+	// Keeping the entry fires no callbacks or events and leaves its tags
+	if evicted != 0 {
+		t.Errorf("Expected no evictions, got %d", evicted)
+	}
+
+	if events := drain(sub); len(events) != 1 {
+		t.Errorf("Expected only the put event, got %v", events)
+	}
+
+	if n := c.InvalidateTag("x"); n != 1 {
+		t.Errorf("Expected tags to be kept, got %d entries removed", n)
+	}
+
+	if v, ok := c.Compute(0, time.Second, func(n int, ok bool) (int, UpdateAction) { return 2, UpdateStore }); !ok || v != 2 {
+		t.Errorf("Expected 2 to be stored, got %d, %v", v, ok)
+	}
+
+	if _, ok := c.Compute(0, time.Second, func(n int, ok bool) (int, UpdateAction) { return 0, UpdateDelete }); ok {
+		t.Error("Expected entry to be removed")
+	}
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected entry to be removed")
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.PutTagged(time.Minute, 0, 1, "x")
+
+	if c.CompareAndSwap(0, 2, 3) {
+		t.Error("Expected swap of a different value to fail")
+	}
+
+	if !c.CompareAndSwap(0, 1, 3) {
+		t.Error("Expected swap to succeed")
+	}
+
+	if v, _ := c.Get(0); v != 3 {
+		t.Errorf("Expected 3, got %d", v)
+	}
+
+	if c.CompareAndSwap(1, 0, 3) {
+		t.Error("Expected swap of a missing entry to fail")
+	}
+
+	// The swap kept the expiry time and tags
+	if n := c.InvalidateTag("x"); n != 1 {
+		t.Errorf("Expected tags to be kept, got %d entries removed", n)
+	}
+
+	c.Put(time.Minute, 0, 1)
+	clock.Advance(2 * time.Minute)
+
+	if c.CompareAndSwap(0, 1, 3) {
+		t.Error("Expected swap of an expired entry to fail")
+	}
+}
+
+func TestCompareAndSwapConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c  = New[int, int]()
+		wg sync.WaitGroup
+	)
+
+	c.Put(time.Minute, 0, 0)
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; {
+				v, _ := c.Get(0)
+				if c.CompareAndSwap(0, v, v+1) {
+					i++
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if v, _ := c.Get(0); v != 800 {
+		t.Errorf("Expected 800, got %d", v)
+	}
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+	)
+
+	if v, loaded := c.PutIfAbsent(time.Minute, 0, 1); loaded || v != 1 {
+		t.Errorf("Expected 1 to be added, got %d, %v", v, loaded)
+	}
+
+	if v, loaded := c.PutIfAbsent(time.Minute, 0, 2); !loaded || v != 1 {
+		t.Errorf("Expected existing 1, got %d, %v", v, loaded)
+	}
+
+	if v, _ := c.Get(0); v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+	)
+
+	c.Put(time.Minute, 0, 1337)
+
+	if v, ok := c.GetAndDelete(0); !ok || v != 1337 {
+		t.Errorf("Expected 1337, got %d, %v", v, ok)
+	}
+
+	if _, ok := c.GetAndDelete(0); ok {
+		t.Error("Expected false, got true")
+	}
+}
+
+func TestUpdateKeepExpiration(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock), WithDefaultExpiration(time.Hour))
+	)
+
+	var incr = func(n int, ok bool) (int, bool) {
+		return n + 1, true
+	}
+
+	c.Put(time.Minute, 0, 0)
+	clock.Advance(30 * time.Second)
+	c.Update(0, KeepExpiration, incr)
+	clock.Advance(31 * time.Second)
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected the expiry time to be kept")
+	}
+
+	// New entries use the default expiration
+	c.Update(1, KeepExpiration, incr)
+	clock.Advance(59 * time.Minute)
+
+	if v, ok := c.Get(1); !ok || v != 1 {
+		t.Errorf("Expected 1, got %d, %v", v, ok)
+	}
+}
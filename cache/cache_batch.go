@@ -0,0 +1,233 @@
+package cache
+
+import "time"
+
+// Timed is a value along with its own duration, see PutManyTimed.
+type Timed[T any] struct {
+	Value    T
+	Duration time.Duration
+}
+
+func (c *cache[I, T]) GetMany(keys []I) (map[I]T, []I) {
+	var (
+		found  = make(map[I]T, len(keys))
+		misses []I
+		stale  []I
+		now    = c.clock.Now()
+	)
+
+	for n, keys := range c.group(keys) {
+		if len(keys) > 0 {
+			stale = c.shards[n].getMany(now, keys, found, stale)
+		}
+	}
+
+	for _, k := range stale {
+		c.refresh(k)
+	}
+
+	for _, k := range keys {
+		if _, ok := found[k]; !ok {
+			misses = append(misses, k)
+		}
+	}
+
+	return found, misses
+}
+
+func (c *cache[I, T]) PutMany(d time.Duration, items map[I]T) {
+	d = c.expiration(d)
+
+	c.putMany(keys(items), func(I) time.Duration { return d }, func(k I) T { return items[k] })
+}
+
+func (c *cache[I, T]) PutManyTimed(items map[I]Timed[T]) {
+	c.putMany(keys(items),
+		func(k I) time.Duration { return c.expiration(items[k].Duration) },
+		func(k I) T { return items[k].Value })
+}
+
+// keys returns the keys of the map.
+func keys[I comparable, V any](mp map[I]V) []I {
+	var ks = make([]I, 0, len(mp))
+	for k := range mp {
+		ks = append(ks, k)
+	}
+
+	return ks
+}
+
+// putMany stores the keys with the duration and value returned by the functions, locking each shard once.
+func (c *cache[I, T]) putMany(keys []I, duration func(I) time.Duration, value func(I) T) {
+	var now = c.clock.Now()
+
+	for n, keys := range c.group(keys) {
+		if len(keys) == 0 {
+			continue
+		}
+
+		var s = c.shards[n]
+
+		s.mu.Lock()
+		for _, k := range keys {
+			var d = duration(k)
+			s.store(now, deadline(now, d), d, k, value(k), s.sliding)
+		}
+		s.unlock()
+	}
+}
+
+func (c *cache[I, T]) RemoveMany(keys []I) int {
+	var (
+		removed int
+		now     = c.clock.Now()
+	)
+
+	for n, keys := range c.group(keys) {
+		if len(keys) == 0 {
+			continue
+		}
+
+		var s = c.shards[n]
+
+		s.mu.Lock()
+		for _, k := range keys {
+			it, ok := s.mp[k]
+			if !ok {
+				continue
+			}
+
+			var reason = removeReason(it, now)
+			if reason == EvictRemoved && it.err == nil {
+				removed++
+			}
+
+			s.delete(k, it, reason)
+		}
+		s.unlock()
+	}
+
+	return removed
+}
+
+// getMany adds the live values of the keys to found and appends the stale keys, it reads under the
+// read lock and takes the write lock at most once, to drop expired entries or renew sliding ones.
+func (s *shard[I, T]) getMany(now time.Time, keys []I, found map[I]T, stale []I) []I {
+	if s.pol != nil {
+		return s.getManyLRU(now, keys, found, stale)
+	}
+
+	var (
+		hits, misses, errorHits uint64
+
+		// Keys that need the write lock
+		pending []I
+	)
+
+	s.mu.RLock()
+	for _, k := range keys {
+		it, ok := s.mp[k]
+
+		switch {
+		case !ok:
+			misses++
+
+		case it.expired(now):
+			misses++
+			pending = append(pending, k)
+
+		default:
+			s.access(it, now)
+
+			if it.err == nil {
+				hits++
+				found[k] = it.v
+			} else {
+				errorHits++
+			}
+
+			if it.stale(now) {
+				stale = append(stale, k)
+			}
+
+			if it.sliding {
+				pending = append(pending, k)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	s.stats.hits.Add(hits)
+	s.stats.misses.Add(misses)
+	s.stats.errorHits.Add(errorHits)
+
+	if len(pending) == 0 {
+		return stale
+	}
+
+	s.mu.Lock()
+	for _, k := range pending {
+		it, ok := s.mp[k]
+		if !ok {
+			continue
+		}
+
+		if it.expired(now) {
+			s.delete(k, it, EvictExpired)
+			continue
+		}
+
+		if it.sliding {
+			s.renew(k, it, now)
+		}
+	}
+	s.unlock()
+
+	return stale
+}
+
+// getManyLRU is getMany for bounded caches, where every hit updates the recency list.
+func (s *shard[I, T]) getManyLRU(now time.Time, keys []I, found map[I]T, stale []I) []I {
+	var hits, misses, errorHits uint64
+
+	s.mu.Lock()
+	for _, k := range keys {
+		it, ok := s.mp[k]
+
+		switch {
+		case !ok:
+			misses++
+
+		case it.expired(now):
+			misses++
+			s.delete(k, it, EvictExpired)
+
+		default:
+			s.access(it, now)
+
+			if it.err == nil {
+				hits++
+				found[k] = it.v
+			} else {
+				errorHits++
+			}
+
+			if it.stale(now) {
+				stale = append(stale, k)
+			}
+
+			if it.sliding {
+				s.renew(k, it, now)
+			}
+
+			s.touch(it)
+		}
+	}
+	s.unlock()
+
+	s.stats.hits.Add(hits)
+	s.stats.misses.Add(misses)
+	s.stats.errorHits.Add(errorHits)
+
+	return stale
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetMany(t *testing.T) {
+	t.Parallel()
+
+	var clock = NewFakeClock(time.Now())
+
+	for _, c := range []Cache[int, int]{
+		New[int, int](WithClock(clock), WithShards(4)),
+		NewLRU[int, int](100, WithClock(clock), WithShards(4)),
+	} {
+		c.PutMany(time.Minute, map[int]int{0: 0, 1: 10, 2: 20})
+		c.Put(time.Second, 3, 30)
+		c.PutError(time.Minute, 4, errors.New("nope"))
+
+		clock.Advance(2 * time.Second)
+
+		found, misses := c.GetMany([]int{5, 0, 1, 3, 2, 4})
+
+		if expect := map[int]int{0: 0, 1: 10, 2: 20}; !reflect.DeepEqual(found, expect) {
+			t.Errorf("Expected %v, got %v", expect, found)
+		}
+
+		if expect := []int{5, 3, 4}; !reflect.DeepEqual(misses, expect) {
+			t.Errorf("Expected misses %v, got %v", expect, misses)
+		}
+
+		if st := c.Stats(); st.Hits != 3 || st.Misses != 2 || st.ErrorHits != 1 || st.Expirations != 1 {
+			t.Errorf("Expected 3 hits, 2 misses, 1 error hit and 1 expiration, got %+v", st)
+		}
+	}
+}
+
+func TestGetManyLRU(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](3)
+	)
+
+	c.PutMany(time.Minute, map[int]int{0: 0, 1: 1, 2: 2})
+
+	// Reading 0 and 1 makes 2 the least recently used entry
+	c.GetMany([]int{0, 1})
+	c.Put(time.Minute, 3, 3)
+
+	if _, ok := c.Get(2); ok {
+		t.Error("Expected 2 to be evicted")
+	}
+}
+
+func TestGetManySliding(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.PutSliding(time.Minute, 0, 0)
+
+	clock.Advance(40 * time.Second)
+	c.GetMany([]int{0})
+	clock.Advance(40 * time.Second)
+
+	if _, ok := c.Get(0); !ok {
+		t.Error("Expected GetMany to renew the sliding entry")
+	}
+}
+
+func TestPutManyTimed(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock), WithShards(4), WithDefaultExpiration(time.Hour))
+	)
+
+	c.PutManyTimed(map[int]Timed[int]{
+		0: {Value: 0, Duration: time.Second},
+		1: {Value: 10, Duration: time.Minute},
+		2: {Value: 20, Duration: NoExpiration},
+		3: {Value: 30},
+	})
+
+	clock.Advance(2 * time.Second)
+
+	if found, _ := c.GetMany([]int{0, 1, 2, 3}); !reflect.DeepEqual(found, map[int]int{1: 10, 2: 20, 3: 30}) {
+		t.Errorf("Expected 1, 2 and 3, got %v", found)
+	}
+
+	clock.Advance(2 * time.Minute)
+
+	if found, _ := c.GetMany([]int{1, 2, 3}); !reflect.DeepEqual(found, map[int]int{2: 20, 3: 30}) {
+		t.Errorf("Expected 2 and 3, got %v", found)
+	}
+
+	clock.Advance(time.Hour)
+
+	if found, _ := c.GetMany([]int{2, 3}); !reflect.DeepEqual(found, map[int]int{2: 20}) {
+		t.Errorf("Expected 2, got %v", found)
+	}
+}
+
+func TestRemoveMany(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock   = NewFakeClock(time.Now())
+		c       = New[int, int](WithClock(clock), WithShards(4))
+		reasons = make(map[int]EvictReason)
+	)
+
+	c.OnEvict(func(k, v int, reason EvictReason) {
+		reasons[k] = reason
+	})
+
+	c.PutMany(time.Minute, map[int]int{0: 0, 1: 10, 2: 20})
+	c.Put(time.Second, 3, 30)
+
+	clock.Advance(2 * time.Second)
+
+	if n := c.RemoveMany([]int{0, 1, 3, 4}); n != 2 {
+		t.Errorf("Expected 2 removed entries, got %d", n)
+	}
+
+	if n := c.Len(); n != 1 {
+		t.Errorf("Expected 1 entry left, got %d", n)
+	}
+
+	if expect := map[int]EvictReason{0: EvictRemoved, 1: EvictRemoved, 3: EvictExpired}; !reflect.DeepEqual(reasons, expect) {
+		t.Errorf("Expected %v, got %v", expect, reasons)
+	}
+}
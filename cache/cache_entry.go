@@ -0,0 +1,72 @@
+package cache
+
+import "time"
+
+// Entry is a cached value along with its metadata, see GetEntry.
+type Entry[T any] struct {
+	Value T
+
+	// When the value was stored, Update with KeepExpiration and CompareAndSwap keep it
+	Added time.Time
+	// Zero when the entry never expires
+	Expires time.Time
+
+	// Reads of the value and the last one, only counted with WithAccessTracking
+	LastAccess time.Time
+	Hits       uint64
+}
+
+func (c *cache[I, T]) GetWithExpiry(i I) (T, time.Time, bool) {
+	v, err, t, ok, stale := c.shard(i).get(i)
+	if stale {
+		c.refresh(i)
+	}
+
+	if !ok || err != nil {
+		var zero T
+		return zero, time.Time{}, false
+	}
+
+	return v, t, true
+}
+
+func (c *cache[I, T]) GetEntry(i I) (Entry[T], bool) {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+	)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok := s.mp[i]
+	if !ok || it.expired(now) || it.err != nil {
+		return Entry[T]{}, false
+	}
+
+	var e = Entry[T]{
+		Value: it.v,
+
+		Added:   it.added,
+		Expires: it.t,
+
+		Hits: it.hits.Load(),
+	}
+
+	if ns := it.accessed.Load(); ns != 0 {
+		e.LastAccess = time.Unix(0, ns)
+	}
+
+	return e, true
+}
+
+// access records a read of the item when tracking is enabled, must be called with the lock held,
+// the read lock is enough.
+func (s *shard[I, T]) access(it *cacheItem[T], now time.Time) {
+	if !s.tracking {
+		return
+	}
+
+	it.hits.Add(1)
+	it.accessed.Store(now.UnixNano())
+}
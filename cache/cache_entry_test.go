@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetWithExpiry(t *testing.T) {
+	t.Parallel()
+
+	for _, create := range []func(...Option) Cache[int, int]{
+		New[int, int],
+		func(opts ...Option) Cache[int, int] { return NewLRU[int, int](10, opts...) },
+	} {
+		var (
+			clock = NewFakeClock(time.Now())
+			c     = create(WithClock(clock))
+			now   = clock.Now()
+		)
+
+		c.Put(time.Minute, 0, 1337)
+		c.Put(NoExpiration, 1, 1)
+		c.PutSliding(time.Minute, 2, 2)
+		c.PutError(time.Minute, 3, errors.New("nope"))
+
+		if v, exp, ok := c.GetWithExpiry(0); !ok || v != 1337 || !exp.Equal(now.Add(time.Minute)) {
+			t.Errorf("Expected 1337 expiring at %v, got %d, %v, %v", now.Add(time.Minute), v, exp, ok)
+		}
+
+		if _, exp, ok := c.GetWithExpiry(1); !ok || !exp.IsZero() {
+			t.Errorf("Expected no expiry, got %v, %v", exp, ok)
+		}
+
+		clock.Advance(30 * time.Second)
+
+		// Sliding entries report the renewed expiry
+		if _, exp, ok := c.GetWithExpiry(2); !ok || !exp.Equal(clock.Now().Add(time.Minute)) {
+			t.Errorf("Expected expiry at %v, got %v, %v", clock.Now().Add(time.Minute), exp, ok)
+		}
+
+		if _, _, ok := c.GetWithExpiry(3); ok {
+			t.Error("Expected cached errors to be a miss")
+		}
+
+		clock.Advance(time.Minute)
+
+		if _, exp, ok := c.GetWithExpiry(0); ok || !exp.IsZero() {
+			t.Errorf("Expected expired entry to be a miss, got %v, %v", exp, ok)
+		}
+	}
+}
+
+func TestGetEntry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock), WithAccessTracking())
+		added = clock.Now()
+	)
+
+	c.Put(time.Minute, 0, 1337)
+
+	if e, ok := c.GetEntry(0); !ok || e.Value != 1337 || !e.Added.Equal(added) || !e.Expires.Equal(added.Add(time.Minute)) {
+		t.Errorf("Expected entry added at %v, got %+v, %v", added, e, ok)
+	} else if e.Hits != 0 || !e.LastAccess.IsZero() {
+		t.Errorf("Expected no reads, got %+v", e)
+	}
+
+	clock.Advance(time.Second)
+	c.Get(0)
+	clock.Advance(time.Second)
+	c.GetMany([]int{0})
+
+	// GetEntry itself is not a read
+	c.GetEntry(0)
+
+	if e, _ := c.GetEntry(0); e.Hits != 2 || !e.LastAccess.Equal(clock.Now()) {
+		t.Errorf("Expected 2 hits, last at %v, got %+v", clock.Now(), e)
+	}
+
+	if st := c.Stats(); st.Hits != 2 {
+		t.Errorf("Expected 2 hits in the stats, got %d", st.Hits)
+	}
+
+	// Storing a new value starts over
+	c.Put(time.Minute, 0, 1)
+
+	if e, _ := c.GetEntry(0); e.Hits != 0 || !e.Added.Equal(clock.Now()) {
+		t.Errorf("Expected a fresh entry, got %+v", e)
+	}
+
+	clock.Advance(2 * time.Minute)
+
+	if _, ok := c.GetEntry(0); ok {
+		t.Error("Expected false, got true")
+	}
+}
+
+func TestGetEntryUntracked(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](10)
+	)
+
+	c.Put(time.Minute, 0, 1337)
+	c.Get(0)
+
+	if e, ok := c.GetEntry(0); !ok || e.Hits != 0 || !e.LastAccess.IsZero() {
+		t.Errorf("Expected no access tracking, got %+v, %v", e, ok)
+	}
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+func (c *cache[I, T]) PutError(d time.Duration, i I, err error) {
+	if err == nil {
+		panic("expected an error")
+	}
+
+	var (
+		s    = c.shard(i)
+		now  = c.clock.Now()
+		zero T
+	)
+
+	d = c.expiration(d)
+
+	s.mu.Lock()
+	old, live := s.live(i, now)
+	if it := s.insert(now, deadline(now, d), d, i, zero, false); it != nil {
+		it.err = err
+
+		// The value is no longer readable, for watchers this is a removal
+		if live {
+			s.notify(EventRemove, i, old)
+		}
+	}
+	s.unlock()
+}
+
+func (c *cache[I, T]) GetErr(i I) (T, error) {
+	v, err, ok := c.lookup(i)
+	if !ok {
+		return v, ErrNotFound
+	}
+
+	return v, cachedError(err)
+}
+
+// cachedError wraps an error read from the cache so it can be told apart from a fresh one.
+func cachedError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrCached, err)
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPutError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.PutError(time.Second, 0, io.EOF)
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected Get to treat a cached error as a miss")
+	}
+
+	if _, err := c.GetErr(0); !errors.Is(err, ErrCached) || !errors.Is(err, io.EOF) {
+		t.Errorf("Expected a cached %v, got %v", io.EOF, err)
+	}
+
+	if _, err := c.GetErr(1); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+
+	if n := c.Len(); n != 0 {
+		t.Errorf("Expected cached errors to not count as entries, got %d", n)
+	}
+
+	clock.Advance(2 * time.Second)
+
+	if _, err := c.GetErr(0); err != ErrNotFound {
+		t.Errorf("Expected error %v after expiry, got %v", ErrNotFound, err)
+	}
+}
+
+func TestPutErrorReplace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+
+		evicted int
+	)
+
+	c.OnEvict(func(int, int, EvictReason) {
+		evicted++
+	})
+
+	c.PutError(time.Minute, 0, io.EOF)
+	c.Put(time.Minute, 0, 1337)
+
+	if v, err := c.GetErr(0); err != nil || v != 1337 {
+		t.Errorf("Expected 1337, got %d, %v", v, err)
+	}
+
+	if evicted != 0 {
+		t.Errorf("Expected no eviction callback for a cached error, got %d", evicted)
+	}
+
+	c.PutError(time.Minute, 0, io.EOF)
+
+	if evicted != 1 {
+		t.Errorf("Expected 1 eviction callback, got %d", evicted)
+	}
+
+	if _, ok := c.Remove(0); ok {
+		t.Error("Expected Remove to not return a value for a cached error")
+	}
+}
+
+func TestGetOrLoadErrorExpiration(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock), WithErrorExpiration(time.Second))
+
+		calls int
+	)
+
+	var loader = func(i int) (int, error) {
+		calls++
+		return 0, io.EOF
+	}
+
+	if _, err := c.GetOrLoad(0, time.Minute, loader); err != io.EOF {
+		t.Errorf("Expected error %v, got %v", io.EOF, err)
+	}
+
+	if _, err := c.GetOrLoad(0, time.Minute, loader); !errors.Is(err, ErrCached) || !errors.Is(err, io.EOF) {
+		t.Errorf("Expected a cached %v, got %v", io.EOF, err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 loader call, got %d", calls)
+	}
+
+	clock.Advance(2 * time.Second)
+
+	c.GetOrLoad(0, time.Minute, loader)
+
+	if calls != 2 {
+		t.Errorf("Expected the error to expire, got %d loader calls", calls)
+	}
+}
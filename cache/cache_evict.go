@@ -0,0 +1,102 @@
+package cache
+
+import "time"
+
+type EvictReason int
+
+const (
+	// EvictExpired is used for entries dropped after their duration passed.
+	EvictExpired EvictReason = iota
+	// EvictRemoved is used for entries removed explicitly.
+	EvictRemoved
+	// EvictReplaced is used for values overwritten by a new Put on the same key.
+	EvictReplaced
+	// EvictCapacity is used for entries dropped to make room in a bounded cache.
+	EvictCapacity
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictRemoved:
+		return "removed"
+	case EvictReplaced:
+		return "replaced"
+	case EvictCapacity:
+		return "capacity"
+	default:
+		return "unknown"
+	}
+}
+
+type eviction[I comparable, T any] struct {
+	k I
+	v T
+	r EvictReason
+}
+
+func (c *cache[I, T]) OnEvict(fn func(I, T, EvictReason)) {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.onEvict = append(s.onEvict, fn)
+		s.mu.Unlock()
+	}
+}
+
+// evict queues the eviction callbacks for the entry and notifies its watchers, must be called
+// with the lock held. Cached errors hold no value and are skipped.
+func (s *shard[I, T]) evict(i I, it *cacheItem[T], reason EvictReason) {
+	if it.err != nil {
+		return
+	}
+
+	switch reason {
+	case EvictExpired:
+		s.notify(EventExpire, i, it.v)
+	case EvictRemoved:
+		s.notify(EventRemove, i, it.v)
+	case EvictCapacity:
+		s.notify(EventEvict, i, it.v)
+	}
+
+	if len(s.onEvict) == 0 {
+		return
+	}
+
+	s.evicted = append(s.evicted, eviction[I, T]{k: i, v: it.v, r: reason})
+}
+
+// unlock releases the write lock and then runs the callbacks for the entries evicted while it was held.
+func (s *shard[I, T]) unlock() {
+	var (
+		evicted = s.evicted
+		fns     = s.onEvict
+	)
+	s.evicted = nil
+	s.mu.Unlock()
+
+	for _, e := range evicted {
+		for _, fn := range fns {
+			fn(e.k, e.v, e.r)
+		}
+	}
+}
+
+// replaceReason returns the reason used for the old value of an entry overwritten at the given time.
+func replaceReason[T any](it *cacheItem[T], now time.Time) EvictReason {
+	if it.expired(now) {
+		return EvictExpired
+	}
+
+	return EvictReplaced
+}
+
+// removeReason returns the reason used for an entry removed explicitly at the given time.
+func removeReason[T any](it *cacheItem[T], now time.Time) EvictReason {
+	if it.expired(now) {
+		return EvictExpired
+	}
+
+	return EvictRemoved
+}
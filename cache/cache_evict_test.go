@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvictReasons(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](2)
+
+		got []EvictReason
+	)
+
+	c.OnEvict(func(k, v int, r EvictReason) {
+		got = append(got, r)
+	})
+
+	c.Put(time.Minute, 0, 0)
+	c.Put(time.Minute, 0, 1) // replaced
+	c.Remove(0)              // removed
+
+	c.Put(time.Minute, 1, 1)
+	c.Put(time.Minute, 2, 2)
+	c.Put(time.Minute, 3, 3) // 1 evicted for capacity
+
+	c.Put(time.Millisecond, 4, 4) // 2 evicted for capacity
+	time.Sleep(5 * time.Millisecond)
+	c.Get(4) // expired
+
+	c.Put(time.Millisecond, 5, 5)
+	time.Sleep(5 * time.Millisecond)
+	c.Clean() // expired
+
+	var expect = []EvictReason{
+		EvictReplaced,
+		EvictRemoved,
+		EvictCapacity,
+		EvictCapacity,
+		EvictExpired,
+		EvictExpired,
+	}
+
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v, got %v", expect, got)
+	}
+}
+
+func TestEvictValues(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[string, int]()
+
+		keys   []string
+		values []int
+	)
+
+	c.OnEvict(func(k string, v int, r EvictReason) {
+		keys = append(keys, k)
+		values = append(values, v)
+	})
+
+	c.Put(time.Minute, "a", 1)
+	c.Put(time.Minute, "a", 2)
+	c.Remove("a")
+
+	if expect := []string{"a", "a"}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("Expected %v, got %v", expect, keys)
+	}
+
+	if expect := []int{1, 2}; !reflect.DeepEqual(values, expect) {
+		t.Errorf("Expected %v, got %v", expect, values)
+	}
+}
+
+func TestEvictReentrant(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+	)
+
+	// Would deadlock if callbacks ran with the lock held
+	c.OnEvict(func(k, v int, r EvictReason) {
+		if r == EvictRemoved {
+			c.Put(time.Minute, k+1, v)
+		}
+	})
+
+	c.Put(time.Minute, 0, 1337)
+	c.Remove(0)
+
+	if v, ok := c.Get(1); !ok {
+		t.Error("Expected true, got false")
+	} else if v != 1337 {
+		t.Errorf("Expected 1337, got %d", v)
+	}
+}
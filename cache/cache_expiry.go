@@ -0,0 +1,65 @@
+package cache
+
+import "container/heap"
+
+type expiryEntry[I comparable, T any] struct {
+	k  I
+	it *cacheItem[T]
+}
+
+// expiryHeap orders the entries that can expire by expiry time, so Clean only
+// has to look at the entries that actually expired.
+type expiryHeap[I comparable, T any] []expiryEntry[I, T]
+
+func (h expiryHeap[I, T]) Len() int {
+	return len(h)
+}
+
+func (h expiryHeap[I, T]) Less(a, b int) bool {
+	return h[a].it.t.Before(h[b].it.t)
+}
+
+func (h expiryHeap[I, T]) Swap(a, b int) {
+	h[a], h[b] = h[b], h[a]
+	h[a].it.hi = a
+	h[b].it.hi = b
+}
+
+func (h *expiryHeap[I, T]) Push(x any) {
+	var e = x.(expiryEntry[I, T])
+	e.it.hi = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[I, T]) Pop() any {
+	var (
+		old = *h
+		e   = old[len(old)-1]
+	)
+
+	old[len(old)-1] = expiryEntry[I, T]{}
+	*h = old[:len(old)-1]
+
+	e.it.hi = -1
+	return e
+}
+
+// schedule updates the position of the item in the expiry heap after its expiry time
+// was set, must be called with the lock held.
+func (s *shard[I, T]) schedule(i I, it *cacheItem[T]) {
+	switch {
+	case it.t.IsZero():
+		s.unschedule(it)
+	case it.hi < 0:
+		heap.Push(&s.exp, expiryEntry[I, T]{k: i, it: it})
+	default:
+		heap.Fix(&s.exp, it.hi)
+	}
+}
+
+// unschedule removes the item from the expiry heap, must be called with the lock held.
+func (s *shard[I, T]) unschedule(it *cacheItem[T]) {
+	if it.hi >= 0 {
+		heap.Remove(&s.exp, it.hi)
+	}
+}
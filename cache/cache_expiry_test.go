@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// checkExpiryHeap verifies every entry with an expiry time is in the heap at its recorded position.
+func checkExpiryHeap[I comparable, T any](t *testing.T, s *shard[I, T]) {
+	t.Helper()
+
+	var n int
+	for k, it := range s.mp {
+		if it.t.IsZero() {
+			if it.hi != -1 {
+				t.Errorf("Expected %v to not be in the heap", k)
+			}
+			continue
+		}
+
+		n++
+		if it.hi < 0 || it.hi >= len(s.exp) || s.exp[it.hi].it != it || s.exp[it.hi].k != k {
+			t.Errorf("Expected %v to be in the heap at %d", k, it.hi)
+		}
+	}
+
+	if n != len(s.exp) {
+		t.Errorf("Expected %d heap entries, got %d", n, len(s.exp))
+	}
+
+	for i := 1; i < len(s.exp); i++ {
+		if s.exp[i].it.t.Before(s.exp[(i-1)/2].it.t) {
+			t.Errorf("Expected heap order at %d", i)
+		}
+	}
+}
+
+func TestExpiryHeap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = NewLRU[int, int](50, WithClock(clock))
+		s     = c.(*cache[int, int]).shards[0]
+	)
+
+	for i := 0; i < 100; i++ {
+		switch i % 5 {
+		case 0:
+			c.Put(time.Duration(100-i)*time.Second, i, i)
+		case 1:
+			c.PutSliding(time.Duration(i)*time.Second, i, i)
+		case 2:
+			c.Put(NoExpiration, i, i)
+		case 3:
+			c.Put(time.Duration(i)*time.Second, i-3, i)
+		case 4:
+			c.Remove(i - 2)
+		}
+		c.Touch(i - 10)
+		c.Get(i - 4)
+	}
+
+	checkExpiryHeap(t, s)
+
+	clock.Advance(40 * time.Second)
+	c.Clean()
+
+	checkExpiryHeap(t, s)
+
+	var now = clock.Now()
+	for k, it := range s.mp {
+		if it.expired(now) {
+			t.Errorf("Expected %d to be cleaned", k)
+		}
+	}
+
+	// An entry made permanent leaves the heap
+	c.Put(time.Minute, 1000, 0)
+	c.Put(NoExpiration, 1000, 0)
+
+	checkExpiryHeap(t, s)
+}
+
+func TestExpiryClean(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	for i := 0; i < 10; i++ {
+		c.Put(time.Duration(i)*time.Second+time.Millisecond, i, i)
+	}
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		c.Clean()
+
+		if n := c.Stats().Size; n != 9-i {
+			t.Errorf("Expected %d entries, got %d", 9-i, n)
+		}
+	}
+}
+
+// scanClean is the full scan Clean used before the expiry heap, kept as a benchmark baseline.
+func scanClean[I comparable, T any](c *cache[I, T]) {
+	var now = c.clock.Now()
+
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for k, v := range s.mp {
+			if v.expired(now) {
+				s.delete(k, v, EvictExpired)
+			}
+		}
+		s.unlock()
+	}
+}
+
+// benchmarkClean measures a Clean call, which holds the lock throughout, on a large cache
+// where only a few entries expired.
+func benchmarkClean(b *testing.B, size, expired int, clean func(*cache[int, int])) {
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock)).(*cache[int, int])
+	)
+
+	for i := 0; i < size; i++ {
+		c.Put(1000*time.Hour, i, i)
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		for i := 0; i < expired; i++ {
+			c.Put(time.Nanosecond, size+i, i)
+		}
+		clock.Advance(time.Microsecond)
+		b.StartTimer()
+
+		clean(c)
+	}
+}
+
+func BenchmarkClean(b *testing.B) {
+	for _, size := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("heap/size=%d", size), func(b *testing.B) {
+			benchmarkClean(b, size, 100, func(c *cache[int, int]) { c.Clean() })
+		})
+
+		b.Run(fmt.Sprintf("scan/size=%d", size), func(b *testing.B) {
+			benchmarkClean(b, size, 100, scanClean[int, int])
+		})
+	}
+}
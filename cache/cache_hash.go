@@ -0,0 +1,10 @@
+//go:build go1.24
+
+package cache
+
+import "hash/maphash"
+
+// hashKey hashes a key of any comparable type.
+func hashKey[I comparable](seed maphash.Seed, i I) uint64 {
+	return maphash.Comparable(seed, i)
+}
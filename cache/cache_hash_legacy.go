@@ -0,0 +1,63 @@
+//go:build !go1.24
+
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// hashKey hashes a key of any comparable type. Before Go 1.24 there is no generic hash, so
+// strings and numbers are hashed directly, pointers and channels by address, and other keys
+// through their Go syntax representation, which is the same for equal values except for the
+// sign of zero floats inside composite keys. Pointers nested in those print as addresses.
+func hashKey[I comparable](seed maphash.Seed, i I) uint64 {
+	var n uint64
+
+	switch k := any(i).(type) {
+	case string:
+		return maphash.String(seed, k)
+	case int:
+		n = uint64(k)
+	case int8:
+		n = uint64(k)
+	case int16:
+		n = uint64(k)
+	case int32:
+		n = uint64(k)
+	case int64:
+		n = uint64(k)
+	case uint:
+		n = uint64(k)
+	case uint8:
+		n = uint64(k)
+	case uint16:
+		n = uint64(k)
+	case uint32:
+		n = uint64(k)
+	case uint64:
+		n = k
+	case uintptr:
+		n = uint64(k)
+	case float32:
+		// Adding zero turns -0 into 0, which compare equal
+		n = math.Float64bits(float64(k) + 0)
+	case float64:
+		n = math.Float64bits(k + 0)
+	default:
+		// %#v prints what a pointer points to, which can change while the key is cached
+		switch v := reflect.ValueOf(k); v.Kind() {
+		case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+			n = uint64(v.Pointer())
+		default:
+			return maphash.String(seed, fmt.Sprintf("%T %#v", k, k))
+		}
+	}
+
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], n)
+	return maphash.Bytes(seed, b[:])
+}
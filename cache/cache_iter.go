@@ -0,0 +1,56 @@
+package cache
+
+import "time"
+
+func (c *cache[I, T]) Len() int {
+	var (
+		n   int
+		now = c.clock.Now()
+	)
+
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for _, it := range s.mp {
+			if !it.expired(now) && it.err == nil {
+				n++
+			}
+		}
+		s.mu.RUnlock()
+	}
+
+	return n
+}
+
+func (c *cache[I, T]) Keys() []I {
+	var (
+		entries = c.snapshot(c.clock.Now())
+		keys    = make([]I, len(entries))
+	)
+
+	for n, e := range entries {
+		keys[n] = e.Key
+	}
+
+	return keys
+}
+
+func (c *cache[I, T]) Items() map[I]T {
+	var (
+		entries = c.snapshot(c.clock.Now())
+		items   = make(map[I]T, len(entries))
+	)
+
+	for _, e := range entries {
+		items[e.Key] = e.Value
+	}
+
+	return items
+}
+
+func (c *cache[I, T]) Range(fn func(I, T, time.Time) bool) {
+	for _, e := range c.snapshot(c.clock.Now()) {
+		if !fn(e.Key, e.Value, e.Expires) {
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func testIterCache() Cache[int, int] {
+	var c = New[int, int](WithShards(4))
+
+	for i := 0; i < 10; i++ {
+		c.Put(time.Minute, i, i*10)
+	}
+	c.Put(NoExpiration, 10, 100)
+	c.Put(time.Millisecond, 11, 110)
+
+	time.Sleep(5 * time.Millisecond)
+
+	return c
+}
+
+func TestLen(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = testIterCache()
+	)
+
+	if n := c.Len(); n != 11 {
+		t.Errorf("Expected 11 live entries, got %d", n)
+	}
+
+	c.Remove(0)
+
+	if n := c.Len(); n != 10 {
+		t.Errorf("Expected 10 live entries, got %d", n)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keys   = testIterCache().Keys()
+		expect = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	)
+
+	sort.Ints(keys)
+
+	if !reflect.DeepEqual(keys, expect) {
+		t.Errorf("Expected %v, got %v", expect, keys)
+	}
+}
+
+func TestItems(t *testing.T) {
+	t.Parallel()
+
+	var (
+		items  = testIterCache().Items()
+		expect = map[int]int{}
+	)
+
+	for i := 0; i <= 10; i++ {
+		expect[i] = i * 10
+	}
+
+	if !reflect.DeepEqual(items, expect) {
+		t.Errorf("Expected %v, got %v", expect, items)
+	}
+}
+
+func TestRange(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = testIterCache()
+
+		seen = map[int]time.Time{}
+	)
+
+	c.Range(func(k, v int, t time.Time) bool {
+		seen[k] = t
+
+		// Would deadlock if Range held the lock
+		c.Remove(k)
+		return true
+	})
+
+	if len(seen) != 11 {
+		t.Errorf("Expected 11 entries, got %d", len(seen))
+	}
+
+	if !seen[10].IsZero() {
+		t.Errorf("Expected a zero expiry for 10, got %v", seen[10])
+	}
+
+	if seen[0].IsZero() {
+		t.Error("Expected an expiry for 0")
+	}
+
+	if n := c.Len(); n != 0 {
+		t.Errorf("Expected 0 entries, got %d", n)
+	}
+}
+
+func TestRangeStop(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = testIterCache()
+
+		calls int
+	)
+
+	c.Range(func(int, int, time.Time) bool {
+		calls++
+		return calls < 3
+	})
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
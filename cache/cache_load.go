@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type loadCall[T any] struct {
+	done chan struct{}
+	v    T
+	err  error
+
+	// Value of a panic in a context loader, raised again in the callers waiting on it
+	panicked any
+
+	// Callers still waiting, a context load is canceled once they all gave up. Loads started
+	// by GetOrLoad and refresh count as a waiter that never gives up and have no cancel.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newLoadCall[T any](waiters int, cancel context.CancelFunc) *loadCall[T] {
+	return &loadCall[T]{
+		done: make(chan struct{}),
+		err:  ErrLoaderPanics,
+
+		waiters: waiters,
+		cancel:  cancel,
+	}
+}
+
+// endLoad unregisters the load, unless a canceled load was already replaced by a new one.
+func (c *cache[I, T]) endLoad(i I, cl *loadCall[T]) {
+	c.lmu.Lock()
+	if c.calls[i] == cl {
+		delete(c.calls, i)
+	}
+	c.lmu.Unlock()
+
+	close(cl.done)
+}
+
+func (c *cache[I, T]) GetOrLoad(i I, d time.Duration, fn func(I) (T, error)) (T, error) {
+	if v, err, ok := c.lookup(i); ok {
+		return v, cachedError(err)
+	}
+
+	c.lmu.Lock()
+	if cl, ok := c.calls[i]; ok {
+		// Never gives up, so a context load joined here is not canceled
+		cl.waiters++
+		c.lmu.Unlock()
+
+		<-cl.done
+		return cl.v, cl.err
+	}
+
+	var cl = newLoadCall[T](1, nil)
+	c.calls[i] = cl
+	c.lmu.Unlock()
+
+	defer c.endLoad(i, cl)
+
+	// Another loader may have finished between the miss and registering this call
+	if v, err, ok := c.shard(i).peek(i); ok {
+		cl.v, cl.err = v, cachedError(err)
+		return cl.v, cl.err
+	}
+
+	c.shard(i).stats.loads.Add(1)
+	cl.v, cl.err = fn(i)
+	if cl.err == nil {
+		c.Put(d, i, cl.v)
+	} else if c.errTTL != 0 {
+		c.PutError(c.errTTL, i, cl.err)
+	}
+
+	return cl.v, cl.err
+}
+
+func (c *cache[I, T]) GetOrLoadContext(ctx context.Context, i I, d time.Duration, fn func(context.Context, I) (T, error)) (T, error) {
+	if v, err, ok := c.lookup(i); ok {
+		return v, cachedError(err)
+	}
+
+	c.lmu.Lock()
+	var cl, ok = c.calls[i]
+	if !ok {
+		cl = c.loadContext(ctx, i, d, fn)
+		c.calls[i] = cl
+	}
+	cl.waiters++
+	c.lmu.Unlock()
+
+	select {
+	case <-cl.done:
+		if cl.panicked != nil {
+			panic(cl.panicked)
+		}
+		return cl.v, cl.err
+
+	case <-ctx.Done():
+		c.lmu.Lock()
+		if cl.waiters--; cl.waiters == 0 && cl.cancel != nil {
+			// Later callers start over instead of joining the canceled load
+			if c.calls[i] == cl {
+				delete(c.calls, i)
+			}
+			cl.cancel()
+		}
+		c.lmu.Unlock()
+
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// loadContext starts a context load in the background, with the values of the context but without
+// its cancellation, must be called with lmu held.
+func (c *cache[I, T]) loadContext(ctx context.Context, i I, d time.Duration, fn func(context.Context, I) (T, error)) *loadCall[T] {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	var cl = newLoadCall[T](0, cancel)
+
+	go func() {
+		defer func() {
+			cl.panicked = recover()
+			cancel()
+			c.endLoad(i, cl)
+		}()
+
+		// Another loader may have finished between the miss and registering this call
+		if v, err, ok := c.shard(i).peek(i); ok {
+			cl.v, cl.err = v, cachedError(err)
+			return
+		}
+
+		c.shard(i).stats.loads.Add(1)
+		cl.v, cl.err = fn(ctx, i)
+
+		switch {
+		case cl.err == nil:
+			c.Put(d, i, cl.v)
+
+		// The error of a canceled load says nothing about the key
+		case c.errTTL != 0 && ctx.Err() == nil:
+			c.PutError(c.errTTL, i, cl.err)
+		}
+	}()
+
+	return cl
+}
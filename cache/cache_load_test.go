@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+
+		calls int
+	)
+
+	var loader = func(i int) (int, error) {
+		calls++
+		return i * 2, nil
+	}
+
+	for n := 0; n < 2; n++ {
+		if v, err := c.GetOrLoad(21, time.Minute, loader); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		} else if v != 42 {
+			t.Errorf("Expected 42, got %d", v)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 loader call, got %d", calls)
+	}
+}
+
+func TestGetOrLoadCoalesce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+
+		calls   atomic.Int32
+		release = make(chan struct{})
+
+		wg sync.WaitGroup
+	)
+
+	var loader = func(i int) (int, error) {
+		calls.Add(1)
+		<-release
+		return 1337, nil
+	}
+
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if v, err := c.GetOrLoad(0, time.Minute, loader); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			} else if v != 1337 {
+				t.Errorf("Expected 1337, got %d", v)
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 loader call, got %d", n)
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+
+		calls int
+	)
+
+	var loader = func(i int) (int, error) {
+		calls++
+		return 0, io.EOF
+	}
+
+	for n := 0; n < 2; n++ {
+		if _, err := c.GetOrLoad(0, time.Minute, loader); err != io.EOF {
+			t.Errorf("Expected error %v, got %v", io.EOF, err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected errors to not be cached, got %d loader calls", calls)
+	}
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected false, got true")
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+	)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected loader panic to propagate")
+			}
+		}()
+
+		c.GetOrLoad(0, time.Minute, func(int) (int, error) {
+			panic("boom")
+		})
+	}()
+
+	if v, err := c.GetOrLoad(0, time.Minute, func(int) (int, error) { return 1337, nil }); err != nil || v != 1337 {
+		t.Errorf("Expected 1337 after a panicked load, got %d, %v", v, err)
+	}
+}
+
+func TestGetOrLoadContext(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+
+		calls   atomic.Int32
+		release = make(chan struct{})
+		done    = make(chan struct{})
+	)
+
+	// Context loads and plain loads of the same key share a single loader call
+	go func() {
+		defer close(done)
+
+		if v, err := c.GetOrLoad(0, time.Minute, func(i int) (int, error) {
+			calls.Add(1)
+			<-release
+			return 1337, nil
+		}); err != nil || v != 1337 {
+			t.Errorf("Expected 1337, got %d, %v", v, err)
+		}
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	var loader = func(ctx context.Context, i int) (int, error) {
+		calls.Add(1)
+		return 0, io.EOF
+	}
+
+	// Giving up on a load started by GetOrLoad does not cancel it
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.GetOrLoadContext(ctx, 0, time.Minute, loader); err != context.Canceled {
+		t.Errorf("Expected error %v, got %v", context.Canceled, err)
+	}
+
+	close(release)
+	<-done
+
+	if v, err := c.GetOrLoadContext(context.Background(), 0, time.Minute, loader); err != nil || v != 1337 {
+		t.Errorf("Expected cached 1337, got %d, %v", v, err)
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 loader call, got %d", n)
+	}
+
+	if st := c.Stats(); st.Loads != 1 {
+		t.Errorf("Expected 1 load, got %d", st.Loads)
+	}
+}
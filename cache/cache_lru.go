@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"container/list"
+	"time"
+)
+
+// NewLRU creates a cache that holds at most capacity entries, once full, inserting
+// a new key evicts the least recently used one. Both Put and Get count as use.
+func NewLRU[I comparable, T any](capacity int, opts ...Option) Cache[I, T] {
+	if capacity <= 0 {
+		panic("expected a positive capacity")
+	}
+
+	return newCache[I, T](capacity, nil, 0, nil, opts)
+}
+
+// policy decides which entry a bounded shard evicts, entries are tracked through the list element
+// returned by add. Policies are used with the shard lock held.
+type policy[I comparable] interface {
+	// add tracks a newly inserted key.
+	add(I) *list.Element
+	// access records a hit on a tracked key.
+	access(*list.Element)
+	// remove stops tracking a key.
+	remove(*list.Element)
+	// victim returns the key to evict next, there must be one.
+	victim() I
+	// len returns the number of tracked keys.
+	len() int
+	// each calls the function for every tracked key, coldest first.
+	each(func(I))
+}
+
+// lruPolicy evicts the least recently used key, front of the list is most recently used.
+type lruPolicy[I comparable] struct {
+	ll *list.List
+}
+
+func newLRUPolicy[I comparable]() policy[I] {
+	return &lruPolicy[I]{ll: list.New()}
+}
+
+func (p *lruPolicy[I]) add(i I) *list.Element {
+	return p.ll.PushFront(i)
+}
+
+func (p *lruPolicy[I]) access(el *list.Element) {
+	p.ll.MoveToFront(el)
+}
+
+func (p *lruPolicy[I]) remove(el *list.Element) {
+	p.ll.Remove(el)
+}
+
+func (p *lruPolicy[I]) victim() I {
+	return p.ll.Back().Value.(I)
+}
+
+func (p *lruPolicy[I]) len() int {
+	return p.ll.Len()
+}
+
+func (p *lruPolicy[I]) each(fn func(I)) {
+	for el := p.ll.Back(); el != nil; el = el.Prev() {
+		fn(el.Value.(I))
+	}
+}
+
+func (s *shard[I, T]) getLRU(i I) (T, error, time.Time, bool, bool) {
+	var (
+		zero T
+		now  = s.clock.Now()
+	)
+
+	s.mu.Lock()
+	v, ok := s.mp[i]
+	if !ok {
+		s.mu.Unlock()
+
+		s.stats.misses.Add(1)
+		return zero, nil, time.Time{}, false, false
+	}
+
+	if v.expired(now) {
+		s.delete(i, v, EvictExpired)
+		s.unlock()
+
+		s.stats.misses.Add(1)
+		return zero, nil, time.Time{}, false, false
+	}
+
+	if v.sliding {
+		s.renew(i, v, now)
+	}
+
+	var stale = v.stale(now)
+
+	s.touch(v)
+	s.access(v, now)
+	val, err, t := v.v, v.err, v.t
+	s.mu.Unlock()
+
+	s.hit(err)
+	return val, err, t, true, stale
+}
+
+// track adds a newly inserted item to the eviction policy and evicts the entries
+// over the limits, must be called with the lock held.
+func (s *shard[I, T]) track(i I, it *cacheItem[T]) {
+	if s.pol == nil {
+		return
+	}
+
+	it.el = s.pol.add(i)
+	s.trim()
+}
+
+// trim evicts the entries chosen by the policy until the shard is within its capacity
+// and weight limits, must be called with the lock held.
+func (s *shard[I, T]) trim() {
+	if s.pol == nil {
+		return
+	}
+
+	for (s.capacity > 0 && s.pol.len() > s.capacity) || (s.maxWeight > 0 && s.weight > s.maxWeight) {
+		var k = s.pol.victim()
+		s.delete(k, s.mp[k], EvictCapacity)
+	}
+}
+
+// split returns the share of the total limit given to the nth of the shards.
+func split(total int64, shards, n int) int64 {
+	var share = total / int64(shards)
+	if int64(n) < total%int64(shards) {
+		share++
+	}
+
+	return share
+}
+
+// touch records a use of the item with the eviction policy, must be called with the lock held.
+func (s *shard[I, T]) touch(it *cacheItem[T]) {
+	if it.el != nil {
+		s.pol.access(it.el)
+	}
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRUEvict(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](2)
+	)
+
+	c.Put(time.Minute, 0, 0)
+	c.Put(time.Minute, 1, 1)
+
+	// Reading 0 makes 1 the least recently used entry
+	if _, ok := c.Get(0); !ok {
+		t.Error("Expected true, got false")
+	}
+
+	c.Put(time.Minute, 2, 2)
+
+	if _, ok := c.Get(1); ok {
+		t.Error("Expected 1 to be evicted")
+	}
+
+	for _, k := range []int{0, 2} {
+		if v, ok := c.Get(k); !ok {
+			t.Errorf("Expected %d to be present", k)
+		} else if v != k {
+			t.Errorf("Expected %d, got %d", k, v)
+		}
+	}
+}
+
+func TestLRUReplace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](2)
+	)
+
+	c.Put(time.Minute, 0, 0)
+	c.Put(time.Minute, 1, 1)
+	c.Put(time.Minute, 0, 1337)
+	c.Put(time.Minute, 2, 2)
+
+	if _, ok := c.Get(1); ok {
+		t.Error("Expected 1 to be evicted")
+	}
+
+	if v, ok := c.Get(0); !ok {
+		t.Error("Expected true, got false")
+	} else if v != 1337 {
+		t.Errorf("Expected 1337, got %d", v)
+	}
+
+	if n := len(c.(*cache[int, int]).shards[0].mp); n != 2 {
+		t.Errorf("Expected 2 entries, got %d", n)
+	}
+}
+
+func TestLRUExpire(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](2)
+	)
+
+	c.Put(5*time.Millisecond, 0, 1337)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected false, got true")
+	}
+
+	if n := c.(*cache[int, int]).shards[0].pol.len(); n != 0 {
+		t.Errorf("Expected empty recency list, got %d entries", n)
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c  = NewLRU[int, int](16)
+		wg sync.WaitGroup
+	)
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				c.Put(time.Minute, g*1000+i, i)
+				c.Get(g*1000 + i/2)
+				c.Remove(g*1000 + i/3)
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	var lc = c.(*cache[int, int]).shards[0]
+	if len(lc.mp) > 16 || len(lc.mp) != lc.pol.len() {
+		t.Errorf("Expected at most 16 consistent entries, got %d (list %d)", len(lc.mp), lc.pol.len())
+	}
+}
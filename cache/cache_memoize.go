@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// MemoOption configures Memoize and MemoizeContext.
+type MemoOption[K comparable] func(*memoOptions[K])
+
+type memoOptions[K comparable] struct {
+	bypass func(K) bool
+}
+
+func buildMemoOptions[K comparable](opts []MemoOption[K]) *memoOptions[K] {
+	var o = &memoOptions[K]{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithBypass makes Memoize and MemoizeContext call the function directly, without reading or storing
+// a result, for the arguments the predicate returns true for.
+func WithBypass[K comparable](fn func(K) bool) MemoOption[K] {
+	if fn == nil {
+		panic("expected a bypass function")
+	}
+
+	return func(o *memoOptions[K]) {
+		o.bypass = fn
+	}
+}
+
+// Memoize returns a function caching the results of fn in c for the given duration, through
+// GetOrLoad. Concurrent calls with the same argument share a single call to fn, errors are returned
+// but only cached if the cache was created WithErrorExpiration, and removing an argument from the
+// cache makes the next call compute it again.
+//
+//	var ips = cache.New[string, []net.IP](cache.WithJanitor(time.Minute))
+//	defer ips.Close()
+//
+//	var lookup = cache.Memoize(ips, net.LookupIP, time.Minute)
+//	...
+//	ips.Remove("example.com")
+func Memoize[K comparable, V any](c Cache[K, V], fn func(K) (V, error), d time.Duration, opts ...MemoOption[K]) func(K) (V, error) {
+	if c == nil {
+		panic("expected a cache")
+	}
+
+	if fn == nil {
+		panic("expected a function")
+	}
+
+	var o = buildMemoOptions(opts)
+
+	return func(k K) (V, error) {
+		if o.bypass != nil && o.bypass(k) {
+			return fn(k)
+		}
+
+		return c.GetOrLoad(k, d, fn)
+	}
+}
+
+// MemoizeContext is Memoize for functions taking a context, through GetOrLoadContext. Each caller
+// returns as soon as its own context is done, fn is only canceled once every caller waiting on it
+// gave up.
+//
+//	var get = cache.MemoizeContext(users, func(ctx context.Context, id string) (User, error) {
+//		return db.User(ctx, id)
+//	}, time.Minute)
+func MemoizeContext[K comparable, V any](c Cache[K, V], fn func(context.Context, K) (V, error), d time.Duration, opts ...MemoOption[K]) func(context.Context, K) (V, error) {
+	if c == nil {
+		panic("expected a cache")
+	}
+
+	if fn == nil {
+		panic("expected a function")
+	}
+
+	var o = buildMemoOptions(opts)
+
+	return func(ctx context.Context, k K) (V, error) {
+		if o.bypass != nil && o.bypass(k) {
+			return fn(ctx, k)
+		}
+
+		return c.GetOrLoadContext(ctx, k, d, fn)
+	}
+}
@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		calls atomic.Int32
+		fail  = errors.New("odd")
+	)
+
+	var double = Memoize(New[int, int](WithClock(clock)), func(i int) (int, error) {
+		calls.Add(1)
+		if i%2 != 0 {
+			return 0, fail
+		}
+		return i * 2, nil
+	}, time.Minute)
+
+	for n := 0; n < 2; n++ {
+		if v, err := double(20); err != nil || v != 40 {
+			t.Errorf("Expected 40, got %d, %v", v, err)
+		}
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 call, got %d", n)
+	}
+
+	// Errors are not cached by default
+	for n := 0; n < 2; n++ {
+		if _, err := double(1); err != fail {
+			t.Errorf("Expected error %v, got %v", fail, err)
+		}
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("Expected 3 calls, got %d", n)
+	}
+
+	clock.Advance(2 * time.Minute)
+	double(20)
+
+	if n := calls.Load(); n != 4 {
+		t.Errorf("Expected the expired result to be computed again, got %d calls", n)
+	}
+}
+
+func TestMemoizeCoalesce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+
+	var slow = Memoize(New[int, int](), func(i int) (int, error) {
+		calls.Add(1)
+		<-release
+		return i, nil
+	}, time.Minute)
+
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slow(1)
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 call, got %d", n)
+	}
+}
+
+func TestMemoizeOptions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c     = New[int, int]()
+		calls atomic.Int32
+	)
+
+	var fn = Memoize(c, func(i int) (int, error) {
+		calls.Add(1)
+		return i, nil
+	}, time.Minute, WithBypass(func(i int) bool { return i < 0 }))
+
+	fn(-1)
+	fn(-1)
+	fn(1)
+	fn(1)
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("Expected 3 calls, got %d", n)
+	}
+
+	if _, ok := c.Get(-1); ok {
+		t.Error("Expected bypassed results not to be stored")
+	}
+
+	if v, ok := c.Get(1); !ok || v != 1 {
+		t.Errorf("Expected the result in the given cache, got %d, %v", v, ok)
+	}
+
+	c.Remove(1)
+	fn(1)
+
+	if n := calls.Load(); n != 4 {
+		t.Errorf("Expected the removed result to be computed again, got %d calls", n)
+	}
+}
+
+func TestMemoizeInvalid(t *testing.T) {
+	t.Parallel()
+
+	var (
+		fn        = func(i int) (int, error) { return i, nil }
+		fnContext = func(ctx context.Context, i int) (int, error) { return i, nil }
+	)
+
+	for _, memoize := range []func(){
+		func() { Memoize(nil, fn, time.Minute) },
+		func() { MemoizeContext(nil, fnContext, time.Minute) },
+		func() { Memoize(New[int, int](), nil, time.Minute) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+
+			memoize()
+		}()
+	}
+}
+
+func TestMemoizeErrors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls atomic.Int32
+		fail  = errors.New("fail")
+	)
+
+	var (
+		fn = Memoize(New[int, int](WithErrorExpiration(time.Minute)), func(i int) (int, error) {
+			calls.Add(1)
+			return 0, fail
+		}, time.Minute)
+
+		fnContext = MemoizeContext(New[int, int](WithErrorExpiration(time.Minute)), func(ctx context.Context, i int) (int, error) {
+			calls.Add(1)
+			return 0, fail
+		}, time.Minute)
+	)
+
+	// Both cache errors the same way, later calls get them wrapped with ErrCached
+	for n := 0; n < 3; n++ {
+		if _, err := fn(1); !errors.Is(err, fail) || (n > 0) != errors.Is(err, ErrCached) {
+			t.Errorf("Expected error %v, got %v", fail, err)
+		}
+
+		if _, err := fnContext(context.Background(), 1); !errors.Is(err, fail) || (n > 0) != errors.Is(err, ErrCached) {
+			t.Errorf("Expected error %v, got %v", fail, err)
+		}
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected 2 calls, got %d", n)
+	}
+}
+
+func TestMemoizeContext(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+
+	var fn = MemoizeContext(New[int, int](), func(ctx context.Context, i int) (int, error) {
+		calls.Add(1)
+
+		select {
+		case <-release:
+			return i, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}, time.Minute)
+
+	// A caller giving up does not cancel the call for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	var done = make(chan error)
+	go func() {
+		_, err := fn(ctx, 1)
+		done <- err
+	}()
+
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if v, err := fn(context.Background(), 1); err != nil || v != 1 {
+				t.Errorf("Expected 1, got %d, %v", v, err)
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected error %v, got %v", context.Canceled, err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if v, err := fn(context.Background(), 1); err != nil || v != 1 {
+		t.Errorf("Expected cached 1, got %d, %v", v, err)
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 call, got %d", n)
+	}
+}
+
+func TestMemoizeContextCancel(t *testing.T) {
+	t.Parallel()
+
+	var (
+		canceled = make(chan struct{})
+		calls    atomic.Int32
+	)
+
+	var fn = MemoizeContext(New[int, int](WithErrorExpiration(time.Minute)), func(ctx context.Context, i int) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			close(canceled)
+			return 0, ctx.Err()
+		}
+		return i, nil
+	}, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := fn(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("Expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// Once every caller gave up the call is canceled, and its error is not cached
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the call to be canceled")
+	}
+
+	if v, err := fn(context.Background(), 1); err != nil || v != 1 {
+		t.Errorf("Expected 1, got %d, %v", v, err)
+	}
+}
+
+func TestMemoizeContextPanic(t *testing.T) {
+	t.Parallel()
+
+	var fn = MemoizeContext(New[int, int](), func(ctx context.Context, i int) (int, error) {
+		panic("boom")
+	}, time.Minute)
+
+	// The panic is raised again in the caller, as with Memoize
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected panic %q, got %v", "boom", r)
+		}
+	}()
+
+	fn(context.Background(), 1)
+	t.Error("Expected a panic")
+}
@@ -0,0 +1,70 @@
+package cache
+
+import "time"
+
+func (c *cache[I, T]) PutRefresh(soft, hard time.Duration, i I, v T) {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+		d   = c.expiration(hard)
+	)
+
+	s.mu.Lock()
+	if it := s.store(now, deadline(now, d), d, i, v, s.sliding); it != nil && soft > 0 {
+		it.r, it.rd = now.Add(soft), soft
+	}
+	s.unlock()
+}
+
+func (c *cache[I, T]) SetLoader(loader func(I) (T, error)) {
+	c.lmu.Lock()
+	c.loader = loader
+	c.lmu.Unlock()
+}
+
+// refresh reloads a stale entry in the background, unless a load for the key is already running.
+func (c *cache[I, T]) refresh(i I) {
+	c.lmu.Lock()
+	var loader = c.loader
+	if _, ok := c.calls[i]; ok || loader == nil {
+		c.lmu.Unlock()
+		return
+	}
+
+	var cl = newLoadCall[T](1, nil)
+	c.calls[i] = cl
+	c.lmu.Unlock()
+
+	go func() {
+		defer func() {
+			c.endLoad(i, cl)
+
+			// A panicking loader is treated as a failed reload, callers that joined get ErrLoaderPanics
+			recover()
+		}()
+
+		var s = c.shard(i)
+
+		// Deferred so a panicking loader, which leaves ErrLoaderPanics, counts as a failed reload
+		defer func() {
+			var now = c.clock.Now()
+
+			s.mu.Lock()
+			// Skip entries removed or replaced with a fresh value in the meantime
+			if it, ok := s.mp[i]; ok && !it.expired(now) && it.stale(now) {
+				var soft, hard = it.rd, it.d
+
+				if cl.err != nil {
+					// Wait another soft duration instead of reloading on every read of a failing backend
+					it.r = now.Add(soft)
+				} else if it := s.store(now, deadline(now, hard), hard, i, cl.v, it.sliding); it != nil {
+					it.r, it.rd = now.Add(soft), soft
+				}
+			}
+			s.unlock()
+		}()
+
+		s.stats.loads.Add(1)
+		cl.v, cl.err = loader(i)
+	}()
+}
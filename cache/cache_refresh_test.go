@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitLoads waits until the background loads of the cache are done.
+func waitLoads[I comparable, T any](t *testing.T, c Cache[I, T]) {
+	var (
+		lc       = c.(*cache[I, T])
+		deadline = time.Now().Add(time.Second)
+	)
+
+	for {
+		lc.lmu.Lock()
+		n := len(lc.calls)
+		lc.lmu.Unlock()
+
+		if n == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected background loads to finish")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		calls atomic.Int32
+
+		release = make(chan struct{})
+	)
+
+	var c = New[int, int](WithClock(clock))
+
+	c.SetLoader(func(i int) (int, error) {
+		calls.Add(1)
+		<-release
+		return 1338, nil
+	})
+
+	c.PutRefresh(time.Minute, time.Hour, 0, 1337)
+
+	if v, _ := c.Get(0); v != 1337 {
+		t.Errorf("Expected 1337, got %d", v)
+	}
+
+	clock.Advance(time.Minute)
+
+	// Stale reads return the old value while a single reload runs
+	for n := 0; n < 10; n++ {
+		if v, ok := c.Get(0); !ok || v != 1337 {
+			t.Errorf("Expected stale 1337, got %d, %v", v, ok)
+		}
+	}
+
+	close(release)
+	waitLoads(t, c)
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 reload, got %d", n)
+	}
+
+	if v, ok := c.Get(0); !ok || v != 1338 {
+		t.Errorf("Expected reloaded 1338, got %d, %v", v, ok)
+	}
+
+	// The reloaded entry starts both durations over
+	clock.Advance(59 * time.Second)
+	c.Get(0)
+	waitLoads(t, c)
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected reloaded entry to be fresh, got %d reloads", n)
+	}
+}
+
+func TestRefreshError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		calls atomic.Int32
+	)
+
+	var c = New[int, int](WithClock(clock))
+
+	c.SetLoader(func(i int) (int, error) {
+		calls.Add(1)
+		return 0, io.EOF
+	})
+
+	c.PutRefresh(time.Minute, time.Hour, 0, 1337)
+
+	clock.Advance(30 * time.Minute)
+
+	for n := 0; n < 3; n++ {
+		if v, ok := c.Get(0); !ok || v != 1337 {
+			t.Errorf("Expected stale 1337 after a failed reload, got %d, %v", v, ok)
+		}
+		waitLoads(t, c)
+	}
+
+	// A failed reload waits another soft duration before the next one
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 reload, got %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	c.Get(0)
+	waitLoads(t, c)
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected failed reloads to be retried after the soft duration, got %d reloads", n)
+	}
+
+	clock.Advance(time.Hour)
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected entry to expire after the hard duration")
+	}
+}
+
+func TestRefreshReplaced(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock   = NewFakeClock(time.Now())
+		release = make(chan struct{})
+	)
+
+	var c = NewLRU[int, int](10, WithClock(clock))
+
+	c.SetLoader(func(i int) (int, error) {
+		<-release
+		return 1338, nil
+	})
+
+	c.PutRefresh(time.Minute, time.Hour, 0, 1337)
+
+	clock.Advance(time.Minute)
+	c.Get(0)
+
+	// A fresh value written during the reload wins over the reloaded one
+	c.Put(time.Hour, 0, 1)
+	close(release)
+	waitLoads(t, c)
+
+	if v, _ := c.Get(0); v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+}
+
+func TestRefreshNoLoader(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	// Setting the loader back to nil stops refreshing
+	c.SetLoader(func(i int) (int, error) { return 1338, nil })
+	c.SetLoader(nil)
+
+	c.PutRefresh(time.Minute, time.Hour, 0, 1337)
+
+	clock.Advance(30 * time.Minute)
+
+	if v, ok := c.Get(0); !ok || v != 1337 {
+		t.Errorf("Expected stale 1337, got %d, %v", v, ok)
+	}
+
+	waitLoads(t, c)
+
+	if v, _ := c.Get(0); v != 1337 {
+		t.Errorf("Expected no reload, got %d", v)
+	}
+}
+
+func TestRefreshPanic(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		calls atomic.Int32
+	)
+
+	var c = New[int, int](WithClock(clock))
+
+	c.SetLoader(func(i int) (int, error) {
+		calls.Add(1)
+		panic("boom")
+	})
+
+	c.PutRefresh(time.Minute, time.Hour, 0, 1337)
+
+	clock.Advance(time.Minute)
+
+	for n := 0; n < 3; n++ {
+		if v, ok := c.Get(0); !ok || v != 1337 {
+			t.Errorf("Expected stale 1337 after a panicking reload, got %d, %v", v, ok)
+		}
+		waitLoads(t, c)
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 reload, got %d", n)
+	}
+}
@@ -0,0 +1,32 @@
+package cache
+
+// shard returns the shard responsible for the given key.
+func (c *cache[I, T]) shard(i I) *shard[I, T] {
+	return c.shards[c.index(i)]
+}
+
+// index returns the position of the shard responsible for the given key.
+func (c *cache[I, T]) index(i I) int {
+	if len(c.shards) == 1 {
+		return 0
+	}
+
+	return int(hashKey(c.seed, i) % uint64(len(c.shards)))
+}
+
+// group splits the keys by the position of their shard.
+func (c *cache[I, T]) group(keys []I) [][]I {
+	var groups = make([][]I, len(c.shards))
+
+	if len(c.shards) == 1 {
+		groups[0] = keys
+		return groups
+	}
+
+	for _, k := range keys {
+		var n = c.index(k)
+		groups[n] = append(groups[n], k)
+	}
+
+	return groups
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHashKey(t *testing.T) {
+	t.Parallel()
+
+	type key struct {
+		a string
+		b int
+	}
+
+	var (
+		seed = maphash.MakeSeed()
+		zero = 0.0
+	)
+
+	if hashKey(seed, "a") != hashKey(seed, "a") || hashKey(seed, 1) != hashKey(seed, 1) {
+		t.Error("Expected equal keys to hash the same")
+	}
+
+	if hashKey(seed, key{"a", 1}) != hashKey(seed, key{"a", 1}) {
+		t.Error("Expected equal struct keys to hash the same")
+	}
+
+	if hashKey(seed, zero) != hashKey(seed, -zero) {
+		t.Error("Expected 0 and -0 to hash the same")
+	}
+
+	if hashKey(seed, key{"a", 1}) == hashKey(seed, key{"a", 2}) {
+		t.Error("Expected different keys to hash differently")
+	}
+
+	// Pointer keys hash by address, not by what they point to
+	var p = &key{"a", 1}
+	var h = hashKey(seed, p)
+	p.b++
+
+	if hashKey(seed, p) != h || hashKey(seed, any(p)) != hashKey(seed, any(p)) {
+		t.Error("Expected a pointer key to keep its hash when the value it points to changes")
+	}
+}
+
+func TestShards(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[string, int](WithShards(8))
+
+		wg sync.WaitGroup
+	)
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				c.Put(time.Minute, fmt.Sprint(g, "-", i), i)
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	var used int
+	for _, s := range c.(*cache[string, int]).shards {
+		if len(s.mp) > 0 {
+			used++
+		}
+	}
+
+	if used < 2 {
+		t.Errorf("Expected keys to spread over several shards, got %d", used)
+	}
+
+	for g := 0; g < 8; g++ {
+		for i := 0; i < 100; i++ {
+			if v, ok := c.Get(fmt.Sprint(g, "-", i)); !ok {
+				t.Errorf("Expected %d-%d to be present", g, i)
+			} else if v != i {
+				t.Errorf("Expected %d, got %d", i, v)
+			}
+		}
+	}
+
+	if _, ok := c.Remove("0-0"); !ok {
+		t.Error("Expected true, got false")
+	}
+
+	if _, ok := c.Get("0-0"); ok {
+		t.Error("Expected false, got true")
+	}
+}
+
+func TestShardsCapacity(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](10, WithShards(4))
+
+		total int
+	)
+
+	for _, s := range c.(*cache[int, int]).shards {
+		total += s.capacity
+	}
+
+	if total != 10 {
+		t.Errorf("Expected a total capacity of 10, got %d", total)
+	}
+
+	for i := 0; i < 1000; i++ {
+		c.Put(time.Minute, i, i)
+	}
+
+	var size int
+	for _, s := range c.(*cache[int, int]).shards {
+		size += len(s.mp)
+	}
+
+	if size != 10 {
+		t.Errorf("Expected 10 entries, got %d", size)
+	}
+}
+
+func TestShardsOverCapacity(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+
+	NewLRU[int, int](2, WithShards(4))
+}
+
+func benchmarkParallel(b *testing.B, c Cache[int, int]) {
+	const keys = 1 << 16
+
+	for i := 0; i < keys; i++ {
+		c.Put(time.Hour, i, i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var r = rand.New(rand.NewSource(rand.Int63()))
+
+		for pb.Next() {
+			var k = r.Intn(keys)
+
+			// 1 write every 8 reads
+			if k%8 == 0 {
+				c.Put(time.Hour, k, k)
+			} else {
+				c.Get(k)
+			}
+		}
+	})
+}
+
+func BenchmarkParallel(b *testing.B) {
+	for _, n := range []int{1, runtime.GOMAXPROCS(0) * 4} {
+		b.Run(fmt.Sprintf("shards=%d", n), func(b *testing.B) {
+			benchmarkParallel(b, New[int, int](WithShards(n)))
+		})
+	}
+}
+
+func BenchmarkParallelLRU(b *testing.B) {
+	for _, n := range []int{1, runtime.GOMAXPROCS(0) * 4} {
+		b.Run(fmt.Sprintf("shards=%d", n), func(b *testing.B) {
+			benchmarkParallel(b, NewLRU[int, int](1<<16, WithShards(n)))
+		})
+	}
+}
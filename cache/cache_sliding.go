@@ -0,0 +1,53 @@
+package cache
+
+import "time"
+
+func (c *cache[I, T]) PutSliding(d time.Duration, i I, v T) {
+	c.shard(i).put(c.expiration(d), i, v, true)
+}
+
+func (c *cache[I, T]) Touch(i I) bool {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.mp[i]
+	if !ok || it.expired(now) {
+		return false
+	}
+
+	s.renew(i, it, now)
+	s.touch(it)
+	return true
+}
+
+func (c *cache[I, T]) SetExpiration(i I, d time.Duration) bool {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+	)
+
+	d = c.expiration(d)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.mp[i]
+	if !ok || it.expired(now) {
+		return false
+	}
+
+	it.d = d
+	s.renew(i, it, now)
+	return true
+}
+
+// renew starts the duration of the item over, must be called with the lock held.
+func (s *shard[I, T]) renew(i I, it *cacheItem[T], now time.Time) {
+	it.t = deadline(now, it.d)
+	s.schedule(i, it)
+}
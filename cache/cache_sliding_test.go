@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPutSliding(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.PutSliding(time.Minute, 0, 1337)
+	c.Put(time.Minute, 1, 1337)
+
+	for n := 0; n < 4; n++ {
+		clock.Advance(30 * time.Second)
+
+		if _, ok := c.Get(0); !ok {
+			t.Fatalf("Expected sliding entry to be renewed by Get, expired after %d reads", n)
+		}
+	}
+
+	if _, ok := c.Get(1); ok {
+		t.Error("Expected fixed entry to expire")
+	}
+
+	clock.Advance(time.Minute + 1)
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected sliding entry to expire once not read")
+	}
+}
+
+func TestSlidingExpiration(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = NewLRU[int, int](2, WithSlidingExpiration(), WithClock(clock))
+	)
+
+	c.Put(time.Minute, 0, 1337)
+
+	for n := 0; n < 4; n++ {
+		clock.Advance(30 * time.Second)
+
+		if _, ok := c.Get(0); !ok {
+			t.Fatalf("Expected entry to be renewed by Get, expired after %d reads", n)
+		}
+	}
+}
+
+func TestTouch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.Put(time.Minute, 0, 1337)
+
+	for n := 0; n < 4; n++ {
+		clock.Advance(30 * time.Second)
+
+		if !c.Touch(0) {
+			t.Fatalf("Expected entry to be renewed by Touch, expired after %d touches", n)
+		}
+	}
+
+	if v, ok := c.Get(0); !ok {
+		t.Error("Expected true, got false")
+	} else if v != 1337 {
+		t.Errorf("Expected 1337, got %d", v)
+	}
+
+	if c.Touch(1) {
+		t.Error("Expected Touch on a missing entry to return false")
+	}
+
+	clock.Advance(time.Minute + 1)
+
+	if c.Touch(0) {
+		t.Error("Expected Touch on an expired entry to return false")
+	}
+}
+
+func TestSetExpiration(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock   = NewFakeClock(time.Now())
+		c       = New[int, int](WithClock(clock), WithAccessTracking())
+		sub     = c.Watch(0)
+		evicted int
+	)
+	defer sub.Close()
+
+	c.OnEvict(func(int, int, EvictReason) {
+		evicted++
+	})
+
+	c.PutTagged(time.Minute, 0, 1337, "x")
+	c.Get(0)
+
+	clock.Advance(30 * time.Second)
+
+	if !c.SetExpiration(0, time.Hour) {
+		t.Error("Expected true, got false")
+	}
+
+	clock.Advance(59 * time.Minute)
+
+	e, ok := c.GetEntry(0)
+	if !ok {
+		t.Fatal("Expected the entry to use the new duration")
+	}
+
+	if e.Hits != 1 {
+		t.Errorf("Expected the hits to be kept, got %d", e.Hits)
+	}
+
+	// The entry was neither replaced nor put again
+	if evicted != 0 {
+		t.Errorf("Expected no evictions, got %d", evicted)
+	}
+
+	if events := drain(sub); len(events) != 1 {
+		t.Errorf("Expected only the put event, got %v", events)
+	}
+
+	if n := c.InvalidateTag("x"); n != 1 {
+		t.Errorf("Expected tags to be kept, got %d entries removed", n)
+	}
+
+	if c.SetExpiration(0, time.Hour) {
+		t.Error("Expected SetExpiration on a missing entry to return false")
+	}
+
+	c.Put(time.Minute, 1, 1337)
+	c.SetExpiration(1, NoExpiration)
+	clock.Advance(24 * time.Hour)
+
+	if _, ok := c.Get(1); !ok {
+		t.Error("Expected the entry to never expire")
+	}
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotVersion is written at the start of every snapshot, bump it whenever
+// snapshotHeader or snapshotEntry change in a way older versions cannot read.
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Version int
+	Count   int
+}
+
+type snapshotEntry[I comparable, T any] struct {
+	Key   I
+	Value T
+
+	// Zero when the entry never expires
+	Expires  time.Time
+	Duration time.Duration
+	Sliding  bool
+
+	// Zero when the entry is not refreshed
+	Refresh         time.Time
+	RefreshDuration time.Duration
+
+	Tags []string
+}
+
+func (c *cache[I, T]) Save(w io.Writer) error {
+	var (
+		entries = c.snapshot(c.clock.Now())
+		enc     = c.codec.NewEncoder(w)
+	)
+
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, Count: len(entries)}); err != nil {
+		return err
+	}
+
+	for n := range entries {
+		if err := enc.Encode(&entries[n]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cache[I, T]) Load(r io.Reader) error {
+	var (
+		dec = c.codec.NewDecoder(r)
+		hdr snapshotHeader
+	)
+
+	if err := dec.Decode(&hdr); err != nil {
+		return err
+	}
+
+	if hdr.Version != snapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, hdr.Version)
+	}
+
+	for n := 0; n < hdr.Count; n++ {
+		var e snapshotEntry[I, T]
+		if err := dec.Decode(&e); err != nil {
+			return err
+		}
+
+		var now = c.clock.Now()
+		if !e.Expires.IsZero() && e.Expires.Before(now) {
+			continue
+		}
+
+		var s = c.shard(e.Key)
+
+		s.mu.Lock()
+		if it := s.store(now, e.Expires, e.Duration, e.Key, e.Value, e.Sliding); it != nil {
+			it.r, it.rd = e.Refresh, e.RefreshDuration
+			s.tag(e.Key, it, e.Tags)
+		}
+		s.unlock()
+	}
+
+	return nil
+}
+
+// snapshot returns the live entries of all shards, read while holding every shard lock
+// so the result is consistent across shards.
+func (c *cache[I, T]) snapshot(now time.Time) []snapshotEntry[I, T] {
+	var entries []snapshotEntry[I, T]
+
+	for _, s := range c.shards {
+		s.mu.RLock()
+	}
+
+	for _, s := range c.shards {
+		entries = s.snapshot(now, entries)
+	}
+
+	for _, s := range c.shards {
+		s.mu.RUnlock()
+	}
+
+	return entries
+}
+
+// snapshot appends the live entries of the shard, coldest first for bounded caches,
+// must be called with the lock held.
+func (s *shard[I, T]) snapshot(now time.Time, entries []snapshotEntry[I, T]) []snapshotEntry[I, T] {
+	var add = func(k I, it *cacheItem[T]) {
+		if it.expired(now) || it.err != nil {
+			return
+		}
+
+		entries = append(entries, snapshotEntry[I, T]{
+			Key:   k,
+			Value: it.v,
+
+			Expires:  it.t,
+			Duration: it.d,
+			Sliding:  it.sliding,
+
+			Refresh:         it.r,
+			RefreshDuration: it.rd,
+
+			Tags: it.tags,
+		})
+	}
+
+	if s.pol != nil {
+		s.pol.each(func(k I) {
+			add(k, s.mp[k])
+		})
+	} else {
+		for k, it := range s.mp {
+			add(k, it)
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	for name, codec := range map[string]Codec{"gob": GobCodec, "json": JSONCodec} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				src = New[string, int](WithCodec(codec))
+				dst = New[string, int](WithCodec(codec))
+
+				buf bytes.Buffer
+			)
+
+			src.Put(time.Minute, "a", 1)
+			src.Put(NoExpiration, "b", 2)
+			src.PutSliding(time.Minute, "c", 3)
+
+			if err := src.Save(&buf); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if err := dst.Load(&buf); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for k, expect := range map[string]int{"a": 1, "b": 2, "c": 3} {
+				if v, ok := dst.Get(k); !ok {
+					t.Errorf("Expected %s to be loaded", k)
+				} else if v != expect {
+					t.Errorf("Expected %d, got %d", expect, v)
+				}
+			}
+
+			var (
+				a = src.(*cache[string, int]).shards[0].mp["a"]
+				b = dst.(*cache[string, int]).shards[0].mp["a"]
+			)
+
+			if !a.t.Equal(b.t) {
+				t.Errorf("Expected expiry %v to be kept, got %v", a.t, b.t)
+			}
+
+			if it := dst.(*cache[string, int]).shards[0].mp["b"]; !it.t.IsZero() {
+				t.Errorf("Expected b to never expire, got %v", it.t)
+			}
+
+			if it := dst.(*cache[string, int]).shards[0].mp["c"]; !it.sliding || it.d != time.Minute {
+				t.Errorf("Expected c to be sliding with a 1m duration, got %v, %v", it.sliding, it.d)
+			}
+		})
+	}
+}
+
+func TestSnapshotExpired(t *testing.T) {
+	t.Parallel()
+
+	var (
+		src = New[int, int]()
+		dst = New[int, int]()
+
+		buf bytes.Buffer
+	)
+
+	src.Put(5*time.Millisecond, 0, 1337)
+	src.Put(time.Minute, 1, 1337)
+
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if n := len(dst.(*cache[int, int]).shards[0].mp); n != 1 {
+		t.Errorf("Expected 1 entry, got %d", n)
+	}
+
+	if _, ok := dst.Get(1); !ok {
+		t.Error("Expected true, got false")
+	}
+}
+
+func TestSnapshotLRUOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		src = NewLRU[int, int](3)
+		dst = NewLRU[int, int](3)
+
+		buf bytes.Buffer
+	)
+
+	src.Put(time.Minute, 0, 0)
+	src.Put(time.Minute, 1, 1)
+	src.Put(time.Minute, 2, 2)
+	src.Get(0)
+
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 1 is the least recently used entry in both caches
+	dst.Put(time.Minute, 3, 3)
+
+	if _, ok := dst.Get(1); ok {
+		t.Error("Expected 1 to be evicted")
+	}
+}
+
+func TestSnapshotVersion(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c   = New[int, int]()
+		buf bytes.Buffer
+	)
+
+	gob.NewEncoder(&buf).Encode(snapshotHeader{Version: snapshotVersion + 1})
+
+	if err := c.Load(&buf); !errors.Is(err, ErrSnapshotVersion) {
+		t.Errorf("Expected error %v, got %v", ErrSnapshotVersion, err)
+	}
+}
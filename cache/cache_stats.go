@@ -0,0 +1,107 @@
+package cache
+
+import "sync/atomic"
+
+type Stats struct {
+	// Hits and Misses count the lookups that found or did not find a live value.
+	Hits   uint64
+	Misses uint64
+	// ErrorHits counts the lookups that found a cached error, see PutError, they are neither
+	// hits nor misses.
+	ErrorHits uint64
+
+	// Expirations counts the entries dropped after their duration passed.
+	Expirations uint64
+	// Evictions counts the entries dropped to make room in a bounded cache.
+	Evictions uint64
+	// Removals counts the entries removed explicitly.
+	Removals uint64
+
+	// Rejections counts the values not stored for weighing more than the cache can hold.
+	Rejections uint64
+
+	// Loads counts the loader calls made by GetOrLoad and GetOrLoadContext.
+	Loads uint64
+
+	// Size is the number of entries currently held, including expired entries not yet cleaned.
+	Size int
+	// Weight is the total weight of the entries currently held, zero without a weigher.
+	Weight int64
+}
+
+// HitRatio returns the fraction of lookups that found a value, or 0 when there were none.
+func (s Stats) HitRatio() float64 {
+	var lookups = s.Hits + s.Misses + s.ErrorHits
+	if lookups == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(lookups)
+}
+
+// counters are kept by each shard so lookups on different shards do not contend on them,
+// padded so they never share a cache line with the counters of another shard.
+type counters struct {
+	_ [64]byte
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	errorHits atomic.Uint64
+
+	expirations atomic.Uint64
+	evictions   atomic.Uint64
+	removals    atomic.Uint64
+	rejections  atomic.Uint64
+
+	loads atomic.Uint64
+
+	_ [64]byte
+}
+
+func (c *cache[I, T]) Stats() Stats {
+	var st Stats
+
+	for _, s := range c.shards {
+		st.Hits += s.stats.hits.Load()
+		st.Misses += s.stats.misses.Load()
+		st.ErrorHits += s.stats.errorHits.Load()
+
+		st.Expirations += s.stats.expirations.Load()
+		st.Evictions += s.stats.evictions.Load()
+		st.Removals += s.stats.removals.Load()
+		st.Rejections += s.stats.rejections.Load()
+
+		st.Loads += s.stats.loads.Load()
+
+		s.mu.RLock()
+		st.Size += len(s.mp)
+		st.Weight += s.weight
+		s.mu.RUnlock()
+	}
+
+	return st
+}
+
+func (c *cache[I, T]) ResetStats() {
+	for _, s := range c.shards {
+		s.stats.hits.Store(0)
+		s.stats.misses.Store(0)
+		s.stats.errorHits.Store(0)
+
+		s.stats.expirations.Store(0)
+		s.stats.evictions.Store(0)
+		s.stats.removals.Store(0)
+		s.stats.rejections.Store(0)
+
+		s.stats.loads.Store(0)
+	}
+}
+
+// hit counts a lookup that found a live entry, cached errors are counted apart from values.
+func (s *shard[I, T]) hit(err error) {
+	if err != nil {
+		s.stats.errorHits.Add(1)
+	} else {
+		s.stats.hits.Add(1)
+	}
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewLRU[int, int](2)
+	)
+
+	c.Put(time.Minute, 0, 0)
+	c.Get(0)
+	c.Get(1)
+
+	c.Put(time.Minute, 1, 1)
+	c.Put(time.Minute, 2, 2) // evicts 0
+	c.Remove(1)
+
+	c.Put(time.Millisecond, 3, 3)
+	time.Sleep(5 * time.Millisecond)
+	c.Get(3) // expired
+
+	c.GetOrLoad(4, time.Minute, func(i int) (int, error) { return i, nil })
+	c.GetOrLoad(4, time.Minute, func(i int) (int, error) { return i, nil })
+
+	var expect = Stats{
+		Hits:        2,
+		Misses:      3,
+		Expirations: 1,
+		Evictions:   1,
+		Removals:    1,
+		Loads:       1,
+		Size:        2,
+	}
+
+	if st := c.Stats(); st != expect {
+		t.Errorf("Expected %+v, got %+v", expect, st)
+	}
+
+	if r := c.Stats().HitRatio(); r != 0.4 {
+		t.Errorf("Expected a hit ratio of 0.4, got %v", r)
+	}
+
+	c.ResetStats()
+
+	if st := c.Stats(); st != (Stats{Size: 2}) {
+		t.Errorf("Expected zeroed counters, got %+v", st)
+	}
+}
+
+func TestStatsShards(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int](WithShards(4))
+	)
+
+	for i := 0; i < 100; i++ {
+		c.Put(time.Minute, i, i)
+		c.Get(i)
+	}
+
+	if st := c.Stats(); st.Hits != 100 || st.Size != 100 {
+		t.Errorf("Expected 100 hits and 100 entries, got %+v", st)
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c  = New[int, int](WithShards(4))
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 100; i++ {
+		c.Put(time.Minute, i, i)
+	}
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				c.Get(g*1000 + i%200)
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	// Only the first goroutine reads keys that were added, 500 times
+	if st := c.Stats(); st.Hits != 500 || st.Misses != 3500 {
+		t.Errorf("Expected 500 hits and 3500 misses, got %d and %d", st.Hits, st.Misses)
+	}
+
+	var used int
+	for _, s := range c.(*cache[int, int]).shards {
+		if s.stats.hits.Load()+s.stats.misses.Load() > 0 {
+			used++
+		}
+	}
+
+	if used < 2 {
+		t.Errorf("Expected the lookups to be counted by several shards, got %d", used)
+	}
+
+	c.ResetStats()
+
+	if st := c.Stats(); st.Hits != 0 || st.Misses != 0 || st.Size != 100 {
+		t.Errorf("Expected reset counters and 100 entries, got %+v", st)
+	}
+}
@@ -0,0 +1,72 @@
+package cache
+
+import "time"
+
+func (c *cache[I, T]) PutTagged(d time.Duration, i I, v T, tags ...string) {
+	var (
+		s   = c.shard(i)
+		now = c.clock.Now()
+	)
+
+	d = c.expiration(d)
+
+	s.mu.Lock()
+	if it := s.store(now, deadline(now, d), d, i, v, s.sliding); it != nil {
+		s.tag(i, it, tags)
+	}
+	s.unlock()
+}
+
+func (c *cache[I, T]) InvalidateTag(tag string) int {
+	var (
+		n   int
+		now = c.clock.Now()
+	)
+
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for k := range s.tags[tag] {
+			var it = s.mp[k]
+
+			if it.expired(now) {
+				s.delete(k, it, EvictExpired)
+			} else {
+				s.delete(k, it, EvictRemoved)
+				n++
+			}
+		}
+		s.unlock()
+	}
+
+	return n
+}
+
+// tag adds the item to the index of each tag, must be called with the lock held.
+func (s *shard[I, T]) tag(i I, it *cacheItem[T], tags []string) {
+	for _, tag := range tags {
+		var keys, ok = s.tags[tag]
+		if !ok {
+			keys = make(map[I]struct{})
+			s.tags[tag] = keys
+		}
+
+		if _, ok := keys[i]; !ok {
+			keys[i] = struct{}{}
+			it.tags = append(it.tags, tag)
+		}
+	}
+}
+
+// untag removes the item from the index of its tags, must be called with the lock held.
+func (s *shard[I, T]) untag(i I, it *cacheItem[T]) {
+	for _, tag := range it.tags {
+		var keys = s.tags[tag]
+
+		delete(keys, i)
+		if len(keys) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+
+	it.tags = nil
+}
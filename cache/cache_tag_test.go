@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[string, int](WithShards(4))
+	)
+
+	c.PutTagged(time.Minute, "a1", 1, "a")
+	c.PutTagged(time.Minute, "a2", 2, "a", "all")
+	c.PutTagged(time.Minute, "b1", 3, "b", "all")
+	c.Put(time.Minute, "c1", 4)
+
+	if n := c.InvalidateTag("a"); n != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", n)
+	}
+
+	var keys = c.Keys()
+	sort.Strings(keys)
+
+	if expect := []string{"b1", "c1"}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("Expected %v, got %v", expect, keys)
+	}
+
+	if n := c.InvalidateTag("all"); n != 1 {
+		t.Errorf("Expected 1 entry removed, got %d", n)
+	}
+
+	for _, s := range c.(*cache[string, int]).shards {
+		if len(s.tags) != 0 {
+			t.Errorf("Expected empty tag index, got %v", s.tags)
+		}
+	}
+}
+
+func TestTagIndex(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = NewLRU[int, int](2, WithClock(clock))
+		s     = c.(*cache[int, int]).shards[0]
+	)
+
+	// Replacing an entry drops its old tags
+	c.PutTagged(time.Minute, 0, 0, "x")
+	c.Put(time.Minute, 0, 0)
+
+	if len(s.tags) != 0 {
+		t.Errorf("Expected tags to be dropped by Put, got %v", s.tags)
+	}
+
+	// Remove
+	c.PutTagged(time.Minute, 0, 0, "x")
+	c.Remove(0)
+
+	if len(s.tags) != 0 {
+		t.Errorf("Expected tags to be dropped by Remove, got %v", s.tags)
+	}
+
+	// Capacity eviction
+	c.PutTagged(time.Minute, 0, 0, "x")
+	c.Put(time.Minute, 1, 1)
+	c.Put(time.Minute, 2, 2)
+
+	if len(s.tags) != 0 {
+		t.Errorf("Expected tags to be dropped by eviction, got %v", s.tags)
+	}
+
+	// Lazy expiry and Clean
+	c.PutTagged(time.Second, 3, 3, "x")
+	c.PutTagged(time.Second, 4, 4, "y")
+	clock.Advance(time.Minute)
+	c.Get(3)
+
+	if _, ok := s.tags["x"]; ok {
+		t.Error("Expected tags to be dropped by lazy expiry")
+	}
+
+	c.Clean()
+
+	if len(s.tags) != 0 {
+		t.Errorf("Expected tags to be dropped by Clean, got %v", s.tags)
+	}
+}
+
+func TestTagExpired(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.PutTagged(time.Second, 0, 0, "x")
+	c.PutTagged(time.Hour, 1, 1, "x")
+	clock.Advance(time.Minute)
+
+	if n := c.InvalidateTag("x"); n != 1 {
+		t.Errorf("Expected expired entries to not be counted, got %d", n)
+	}
+}
+
+func TestTagSnapshot(t *testing.T) {
+	t.Parallel()
+
+	var (
+		src = New[int, int]()
+		dst = New[int, int]()
+
+		buf bytes.Buffer
+	)
+
+	src.PutTagged(time.Minute, 0, 0, "x")
+	src.Save(&buf)
+	dst.Load(&buf)
+
+	if n := dst.InvalidateTag("x"); n != 1 {
+		t.Errorf("Expected tags to be restored, got %d entries removed", n)
+	}
+}
@@ -46,3 +46,127 @@ func TestCacheRemove(t *testing.T) {
 		t.Error("Expected false, got true")
 	}
 }
+
+func TestCacheJanitor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int](WithJanitor(time.Millisecond))
+	)
+	defer c.Close()
+
+	c.Put(5*time.Millisecond, 0, 1337)
+	c.Put(time.Minute, 1, 1337)
+
+	var deadline = time.Now().Add(time.Second)
+	for {
+		var lc = c.(*cache[int, int]).shards[0]
+
+		lc.mu.RLock()
+		_, found := lc.mp[0]
+		n := len(lc.mp)
+		lc.mu.RUnlock()
+
+		if !found {
+			if n != 1 {
+				t.Errorf("Expected 1 entry left, got %d", n)
+			}
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected janitor to remove the expired entry")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheClose(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int](WithJanitor(time.Millisecond))
+	)
+
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	select {
+	case <-c.(*cache[int, int]).chDone:
+	default:
+		t.Error("Expected janitor to be stopped")
+	}
+
+	if err := c.Close(); err != ErrClosed {
+		t.Errorf("Expected error %v, got %v", ErrClosed, err)
+	}
+
+	c.Put(time.Minute, 0, 1337)
+	if v, ok := c.Get(0); !ok || v != 1337 {
+		t.Errorf("Expected cache to stay usable after Close, got %d, %v", v, ok)
+	}
+}
+
+func TestCacheNoExpiration(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int]()
+	)
+
+	c.Put(NoExpiration, 0, 1337)
+	c.Put(DefaultExpiration, 1, 1337)
+	c.Set(2, 1337)
+
+	time.Sleep(5 * time.Millisecond)
+	c.Clean()
+
+	for k := 0; k < 3; k++ {
+		if v, ok := c.Get(k); !ok {
+			t.Errorf("Expected %d to never expire", k)
+		} else if v != 1337 {
+			t.Errorf("Expected 1337, got %d", v)
+		}
+	}
+}
+
+func TestCacheDefaultExpiration(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = New[int, int](WithDefaultExpiration(5 * time.Millisecond))
+	)
+
+	c.Put(NoExpiration, 0, 1337)
+	c.Put(DefaultExpiration, 1, 1337)
+	c.Set(2, 1337)
+	c.Put(time.Minute, 3, 1337)
+
+	if _, ok := c.Get(2); !ok {
+		t.Error("Expected true, got false")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	c.Clean()
+
+	var lc = c.(*cache[int, int]).shards[0]
+	for k, expect := range []bool{true, false, false, true} {
+		if _, ok := lc.mp[k]; ok != expect {
+			t.Errorf("Expected presence of %d after Clean to be %v, got %v", k, expect, ok)
+		}
+	}
+}
+
+func TestCacheDefaultExpirationInvalid(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+
+	WithDefaultExpiration(-time.Second)
+}
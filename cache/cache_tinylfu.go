@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"container/list"
+	"hash/maphash"
+)
+
+// NewTinyLFU creates a cache that holds at most capacity entries and evicts them with the W-TinyLFU policy.
+// New keys enter a small LRU window, and once they leave it they are only admitted into the main space if
+// they were used more often than the entry they would evict, as estimated by a frequency sketch that keeps
+// counting evicted keys and halves its counters periodically so old popularity fades. This keeps a hot
+// working set in the cache through scans of cold keys, which would flush a plain LRU. Both Put and Get
+// count as use.
+func NewTinyLFU[I comparable, T any](capacity int, opts ...Option) Cache[I, T] {
+	if capacity <= 0 {
+		panic("expected a positive capacity")
+	}
+
+	return newCache[I, T](capacity, newTinyLFUPolicy[I], 0, nil, opts)
+}
+
+const (
+	segWindow uint8 = iota
+	segProbation
+	segProtected
+)
+
+type lfuNode[I comparable] struct {
+	key  I
+	hash uint64
+	seg  uint8
+}
+
+// tinyLFUPolicy keeps the three segments in a single list, so elements keep their identity when moving
+// between them, separated by two markers:
+//
+//	window (1%) | probation (20% of main) | protected (80% of main)
+//
+// each segment has its most recently used entry first.
+type tinyLFUPolicy[I comparable] struct {
+	ll *list.List
+
+	// Markers at the front of the probation and protected segments
+	probation *list.Element
+	protected *list.Element
+
+	windowLen    int
+	probationLen int
+	protectedLen int
+
+	windowMax    int
+	protectedMax int
+
+	// Last entry moved out of the window, it must win against the main victim to stay
+	candidate *list.Element
+
+	sketch *sketch
+	hash   func(I) uint64
+}
+
+func newTinyLFUPolicy[I comparable](capacity int) policy[I] {
+	var (
+		windowMax = max(1, capacity/100)
+		mainMax   = capacity - windowMax
+	)
+
+	var seed = maphash.MakeSeed()
+
+	var p = &tinyLFUPolicy[I]{
+		ll: list.New(),
+
+		windowMax:    windowMax,
+		protectedMax: mainMax * 8 / 10,
+
+		sketch: newSketch(capacity),
+		hash:   func(i I) uint64 { return hashKey(seed, i) },
+	}
+
+	p.probation = p.ll.PushBack(nil)
+	p.protected = p.ll.PushBack(nil)
+
+	return p
+}
+
+func (p *tinyLFUPolicy[I]) add(i I) *list.Element {
+	var h = p.hash(i)
+	p.sketch.increment(h)
+
+	var el = p.ll.PushFront(&lfuNode[I]{key: i, hash: h, seg: segWindow})
+	p.windowLen++
+
+	if p.windowLen > p.windowMax {
+		var last = p.probation.Prev()
+
+		p.ll.MoveAfter(last, p.probation)
+		last.Value.(*lfuNode[I]).seg = segProbation
+		p.windowLen--
+		p.probationLen++
+
+		p.candidate = last
+	}
+
+	return el
+}
+
+func (p *tinyLFUPolicy[I]) access(el *list.Element) {
+	var n = el.Value.(*lfuNode[I])
+	p.sketch.increment(n.hash)
+
+	switch n.seg {
+	case segWindow:
+		p.ll.MoveToFront(el)
+
+	case segProbation:
+		if el == p.candidate {
+			p.candidate = nil
+		}
+
+		p.ll.MoveAfter(el, p.protected)
+		n.seg = segProtected
+		p.probationLen--
+		p.protectedLen++
+
+		// Demote the least recently used protected entry once the segment is full
+		if p.protectedLen > p.protectedMax {
+			var last = p.ll.Back()
+
+			p.ll.MoveAfter(last, p.probation)
+			last.Value.(*lfuNode[I]).seg = segProbation
+			p.protectedLen--
+			p.probationLen++
+		}
+
+	case segProtected:
+		p.ll.MoveAfter(el, p.protected)
+	}
+}
+
+func (p *tinyLFUPolicy[I]) remove(el *list.Element) {
+	switch el.Value.(*lfuNode[I]).seg {
+	case segWindow:
+		p.windowLen--
+	case segProbation:
+		p.probationLen--
+	case segProtected:
+		p.protectedLen--
+	}
+
+	if el == p.candidate {
+		p.candidate = nil
+	}
+
+	p.ll.Remove(el)
+}
+
+// victim returns the least recently used entry of the main space other than the candidate, falling back to
+// the window, unless the candidate is not used more often than it, in which case the candidate is evicted.
+// The front of the window, the entry just added or used, is never evicted while there is a candidate.
+func (p *tinyLFUPolicy[I]) victim() I {
+	var victim = p.protected.Prev()
+
+	if victim == p.candidate {
+		victim = victim.Prev()
+	}
+
+	// Probation is empty, try protected and then the window
+	if victim == p.probation {
+		victim = p.ll.Back()
+	}
+
+	if victim == p.protected {
+		victim = p.probation.Prev()
+	}
+
+	if victim == nil || (victim == p.ll.Front() && p.candidate != nil) {
+		victim = p.candidate
+	}
+
+	if cand := p.candidate; cand != nil && cand != victim {
+		var c, v = cand.Value.(*lfuNode[I]), victim.Value.(*lfuNode[I])
+
+		// Ties go against the candidate, new keys have to prove themselves
+		if p.sketch.estimate(c.hash) <= p.sketch.estimate(v.hash) {
+			return c.key
+		}
+
+		p.candidate = nil
+	}
+
+	return victim.Value.(*lfuNode[I]).key
+}
+
+func (p *tinyLFUPolicy[I]) len() int {
+	return p.windowLen + p.probationLen + p.protectedLen
+}
+
+func (p *tinyLFUPolicy[I]) each(fn func(I)) {
+	for el := p.ll.Back(); el != nil; el = el.Prev() {
+		if el != p.probation && el != p.protected {
+			fn(el.Value.(*lfuNode[I]).key)
+		}
+	}
+}
+
+const (
+	sketchDepth = 4
+	sketchMax   = 15
+)
+
+// sketch is a count-min sketch estimating how often keys were used, with small counters that are
+// halved once the number of additions reaches ten times the capacity so old frequencies age out.
+// Rows are several times wider than the capacity so that counters of distinct keys rarely collide.
+type sketch struct {
+	table []uint8
+	width uint64
+
+	additions int
+	resetAt   int
+}
+
+func newSketch(capacity int) *sketch {
+	var width uint64 = 64
+	for width < 4*uint64(capacity) {
+		width <<= 1
+	}
+
+	return &sketch{
+		table: make([]uint8, sketchDepth*width),
+		width: width,
+
+		resetAt: 10 * max(capacity, 1),
+	}
+}
+
+// index returns the counter for the hash in the given row, using double hashing on the two halves of the hash.
+func (s *sketch) index(h uint64, row int) uint64 {
+	var step = h>>32 | 1
+	return uint64(row)*s.width + (h+uint64(row)*step)&(s.width-1)
+}
+
+func (s *sketch) increment(h uint64) {
+	for row := 0; row < sketchDepth; row++ {
+		if n := s.index(h, row); s.table[n] < sketchMax {
+			s.table[n]++
+		}
+	}
+
+	if s.additions++; s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *sketch) estimate(h uint64) uint8 {
+	var est uint8 = sketchMax
+
+	for row := 0; row < sketchDepth; row++ {
+		est = min(est, s.table[s.index(h, row)])
+	}
+
+	return est
+}
+
+// reset halves every counter.
+func (s *sketch) reset() {
+	for n := range s.table {
+		s.table[n] >>= 1
+	}
+
+	s.additions /= 2
+}
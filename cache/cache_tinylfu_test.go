@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// zipfTrace returns n keys drawn from a Zipf distribution over the given number of keys.
+func zipfTrace(seed int64, n, keys int) []int {
+	var (
+		r     = rand.New(rand.NewSource(seed))
+		z     = rand.NewZipf(r, 1.01, 1, uint64(keys-1))
+		trace = make([]int, n)
+	)
+
+	for i := range trace {
+		trace[i] = int(z.Uint64())
+	}
+
+	return trace
+}
+
+// scanTrace interleaves a Zipf trace with sequential scans over keys that are never seen again.
+func scanTrace(seed int64, n, keys, every, length int) []int {
+	var (
+		hot   = zipfTrace(seed, n, keys)
+		trace = make([]int, 0, n+n/every*length)
+		cold  = keys
+	)
+
+	for i, k := range hot {
+		trace = append(trace, k)
+
+		if i%every == every-1 {
+			for j := 0; j < length; j++ {
+				trace = append(trace, cold)
+				cold++
+			}
+		}
+	}
+
+	return trace
+}
+
+// hitRatio replays the trace as a read-through cache.
+func hitRatio(c Cache[int, int], trace []int) float64 {
+	for _, k := range trace {
+		if _, ok := c.Get(k); !ok {
+			c.Put(NoExpiration, k, k)
+		}
+	}
+
+	return c.Stats().HitRatio()
+}
+
+func TestTinyLFUZipf(t *testing.T) {
+	t.Parallel()
+
+	var (
+		trace = zipfTrace(1, 200000, 10000)
+		lru   = hitRatio(NewLRU[int, int](500), trace)
+		lfu   = hitRatio(NewTinyLFU[int, int](500), trace)
+	)
+
+	if lfu <= lru {
+		t.Errorf("Expected a better hit ratio than LRU, got %.3f against %.3f", lfu, lru)
+	}
+
+	t.Logf("Zipf hit ratio: TinyLFU %.3f, LRU %.3f", lfu, lru)
+}
+
+func TestTinyLFUScan(t *testing.T) {
+	t.Parallel()
+
+	var (
+		trace = scanTrace(2, 200000, 1000, 1000, 1000)
+		lru   = hitRatio(NewLRU[int, int](500), trace)
+		lfu   = hitRatio(NewTinyLFU[int, int](500), trace)
+	)
+
+	// Scans flush the LRU but are kept out of the main space by the admission policy
+	if lfu < lru*1.2 {
+		t.Errorf("Expected a much better hit ratio than LRU, got %.3f against %.3f", lfu, lru)
+	}
+
+	t.Logf("Scan hit ratio: TinyLFU %.3f, LRU %.3f", lfu, lru)
+}
+
+func TestTinyLFUCapacity(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewTinyLFU[int, int](100, WithShards(4))
+	)
+
+	for _, k := range zipfTrace(3, 10000, 1000) {
+		c.Put(time.Minute, k, k)
+		c.Get(k / 2)
+		if k%7 == 0 {
+			c.Remove(k)
+		}
+	}
+
+	for _, s := range c.(*cache[int, int]).shards {
+		var p = s.pol.(*tinyLFUPolicy[int])
+
+		if len(s.mp) > s.capacity || len(s.mp) != p.len() || p.ll.Len() != p.len()+2 {
+			t.Errorf("Expected at most %d consistent entries, got %d (policy %d, list %d)", s.capacity, len(s.mp), p.len(), p.ll.Len())
+		}
+
+		if p.windowLen > p.windowMax || p.protectedLen > p.protectedMax {
+			t.Errorf("Expected segments within their limits, got window %d and protected %d", p.windowLen, p.protectedLen)
+		}
+
+		var n int
+		p.each(func(k int) {
+			if _, ok := s.mp[k]; !ok {
+				t.Errorf("Expected tracked key %d to be cached", k)
+			}
+			n++
+		})
+
+		if n != len(s.mp) {
+			t.Errorf("Expected %d tracked keys, got %d", len(s.mp), n)
+		}
+	}
+}
+
+func TestTinyLFUAdmission(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewTinyLFU[int, int](10)
+	)
+
+	// A fixed hash keeps the sketch collisions the same on every run
+	c.(*cache[int, int]).shards[0].pol.(*tinyLFUPolicy[int]).hash = func(k int) uint64 {
+		return uint64(k) * 0x9e3779b97f4a7c15
+	}
+
+	// Make 0-9 popular
+	for i := 0; i < 3; i++ {
+		for k := 0; k < 10; k++ {
+			c.Put(time.Minute, k, k)
+			c.Get(k)
+		}
+	}
+
+	// A one-off key goes through the window and is rejected in favor of the popular ones
+	c.Put(time.Minute, 100, 100)
+	c.Put(time.Minute, 101, 101)
+
+	var present int
+	for k := 0; k < 10; k++ {
+		if _, ok := c.Get(k); ok {
+			present++
+		}
+	}
+
+	if present < 9 {
+		t.Errorf("Expected the popular keys to stay cached, got %d of 10", present)
+	}
+}
+
+func TestTinyLFUKeepsNewEntry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c   = NewTinyLFU[int, int](1)
+		sub = c.WatchAll()
+	)
+	defer sub.Close()
+
+	c.Put(time.Minute, 1, 1)
+	for i := 0; i < 5; i++ {
+		c.Get(1)
+	}
+
+	// The popular key is evicted rather than the entry being added
+	c.PutTagged(time.Minute, 2, 2, "x")
+
+	if v, ok := c.Get(2); !ok || v != 2 {
+		t.Errorf("Expected 2, got %d, %v", v, ok)
+	}
+
+	if n := c.InvalidateTag("x"); n != 1 {
+		t.Errorf("Expected 1 entry removed, got %d", n)
+	}
+
+	var expect = []Event[int, int]{
+		{EventPut, 1, 1},
+		{EventEvict, 1, 1},
+		{EventPut, 2, 2},
+		{EventRemove, 2, 2},
+	}
+
+	if events := drain(sub); !reflect.DeepEqual(events, expect) {
+		t.Errorf("Expected %v, got %v", expect, events)
+	}
+}
+
+func TestSketch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s = newSketch(64)
+	)
+
+	for i := 0; i < 20; i++ {
+		s.increment(1)
+	}
+	s.increment(2)
+
+	if n := s.estimate(1); n != sketchMax {
+		t.Errorf("Expected %d, got %d", sketchMax, n)
+	}
+
+	if n := s.estimate(2); n != 1 {
+		t.Errorf("Expected 1, got %d", n)
+	}
+
+	// Reaching ten times the capacity halves every counter
+	for i := s.additions; i < s.resetAt; i++ {
+		s.increment(3)
+	}
+
+	if n := s.estimate(1); n != sketchMax/2 {
+		t.Errorf("Expected %d after aging, got %d", sketchMax/2, n)
+	}
+}
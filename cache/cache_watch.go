@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type EventKind int
+
+const (
+	// EventPut is sent when a value is added or replaced.
+	EventPut EventKind = iota
+	// EventRemove is sent when a value is removed explicitly, by a tag or by being replaced with an error.
+	EventRemove
+	// EventExpire is sent when an expired value is dropped, which happens lazily when the key is used,
+	// or by Clean and the janitor.
+	EventExpire
+	// EventEvict is sent when a value is dropped to make room in a bounded cache.
+	EventEvict
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventPut:
+		return "put"
+	case EventRemove:
+		return "remove"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a change to a key, Value is the new value for EventPut and the old one otherwise.
+type Event[I comparable, T any] struct {
+	Kind  EventKind
+	Key   I
+	Value T
+}
+
+type Subscription[I comparable, T any] interface {
+	// Events returns the channel the events are delivered on, it is closed by Close.
+	Events() <-chan Event[I, T]
+	// Dropped returns how many events were dropped because the buffer was full.
+	Dropped() uint64
+	// Close stops the subscription and closes its channel.
+	Close() error
+}
+
+type subscription[I comparable, T any] struct {
+	w   *watchers[I, T]
+	key I
+	all bool
+
+	ch      chan Event[I, T]
+	dropped atomic.Uint64
+
+	// Guarded by the watchers lock
+	isClosed bool
+}
+
+// watchers holds the subscriptions of a cache, events are sent with the shard lock held
+// so the events of a key are delivered in order.
+type watchers[I comparable, T any] struct {
+	all  map[*subscription[I, T]]struct{}
+	keys map[I]map[*subscription[I, T]]struct{}
+
+	// Number of open subscriptions, checked before taking the lock
+	n atomic.Int64
+
+	buffer int
+
+	mu sync.RWMutex
+}
+
+func newWatchers[I comparable, T any](buffer int) *watchers[I, T] {
+	return &watchers[I, T]{
+		all:  make(map[*subscription[I, T]]struct{}),
+		keys: make(map[I]map[*subscription[I, T]]struct{}),
+
+		buffer: buffer,
+	}
+}
+
+func (c *cache[I, T]) Watch(i I) Subscription[I, T] {
+	return c.watchers.add(i, false)
+}
+
+func (c *cache[I, T]) WatchAll() Subscription[I, T] {
+	var zero I
+	return c.watchers.add(zero, true)
+}
+
+func (w *watchers[I, T]) add(i I, all bool) *subscription[I, T] {
+	var sub = &subscription[I, T]{
+		w:   w,
+		key: i,
+		all: all,
+
+		ch: make(chan Event[I, T], w.buffer),
+	}
+
+	w.mu.Lock()
+	if all {
+		w.all[sub] = struct{}{}
+	} else {
+		var subs, ok = w.keys[i]
+		if !ok {
+			subs = make(map[*subscription[I, T]]struct{})
+			w.keys[i] = subs
+		}
+		subs[sub] = struct{}{}
+	}
+	w.n.Add(1)
+	w.mu.Unlock()
+
+	return sub
+}
+
+// notify sends the event to the subscriptions for the key and for every key,
+// must be called with the shard lock held.
+func (s *shard[I, T]) notify(kind EventKind, i I, v T) {
+	var w = s.watchers
+	if w.n.Load() == 0 {
+		return
+	}
+
+	var ev = Event[I, T]{Kind: kind, Key: i, Value: v}
+
+	w.mu.RLock()
+	for sub := range w.keys[i] {
+		sub.send(ev)
+	}
+	for sub := range w.all {
+		sub.send(ev)
+	}
+	w.mu.RUnlock()
+}
+
+// send delivers the event without blocking, once the buffer is full the oldest event is dropped
+// so a slow consumer always sees the latest changes, must be called with the watchers lock held.
+func (sub *subscription[I, T]) send(ev Event[I, T]) {
+	for {
+		select {
+		case sub.ch <- ev:
+			return
+		default:
+		}
+
+		select {
+		case <-sub.ch:
+			sub.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+func (sub *subscription[I, T]) Events() <-chan Event[I, T] {
+	return sub.ch
+}
+
+func (sub *subscription[I, T]) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+func (sub *subscription[I, T]) Close() error {
+	var w = sub.w
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if sub.isClosed {
+		return ErrClosed
+	}
+	sub.isClosed = true
+
+	if sub.all {
+		delete(w.all, sub)
+	} else if subs := w.keys[sub.key]; subs != nil {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(w.keys, sub.key)
+		}
+	}
+	w.n.Add(-1)
+
+	// No event can be sent anymore, the lock is held by senders
+	close(sub.ch)
+	return nil
+}
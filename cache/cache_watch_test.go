@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// drain returns the events buffered by the subscription.
+func drain[I comparable, T any](sub Subscription[I, T]) []Event[I, T] {
+	var events []Event[I, T]
+
+	for {
+		select {
+		case ev := <-sub.Events():
+			events = append(events, ev)
+		default:
+			return events
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock), WithShards(4))
+		sub   = c.Watch(0)
+	)
+	defer sub.Close()
+
+	c.Put(time.Minute, 0, 1)
+	c.Put(time.Minute, 1, 10)
+	c.Put(time.Second, 0, 2)
+	c.Update(0, KeepExpiration, func(v int, ok bool) (int, bool) { return v + 1, ok })
+
+	clock.Advance(2 * time.Second)
+	c.Clean()
+
+	c.Put(time.Minute, 0, 4)
+	c.Remove(0)
+	c.PutTagged(time.Minute, 0, 5, "x")
+	c.InvalidateTag("x")
+
+	var expect = []Event[int, int]{
+		{EventPut, 0, 1},
+		{EventPut, 0, 2},
+		{EventPut, 0, 3},
+		{EventExpire, 0, 3},
+		{EventPut, 0, 4},
+		{EventRemove, 0, 4},
+		{EventPut, 0, 5},
+		{EventRemove, 0, 5},
+	}
+
+	if events := drain(sub); !reflect.DeepEqual(events, expect) {
+		t.Errorf("Expected %v, got %v", expect, events)
+	}
+}
+
+func TestWatchAll(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c   = NewLRU[int, int](1)
+		sub = c.WatchAll()
+	)
+	defer sub.Close()
+
+	c.Put(time.Minute, 0, 0)
+	c.Put(time.Minute, 1, 10)
+	c.PutError(time.Minute, 1, errors.New("nope"))
+	c.Remove(1)
+
+	var expect = []Event[int, int]{
+		{EventPut, 0, 0},
+		{EventEvict, 0, 0},
+		{EventPut, 1, 10},
+		{EventRemove, 1, 10},
+	}
+
+	if events := drain(sub); !reflect.DeepEqual(events, expect) {
+		t.Errorf("Expected %v, got %v", expect, events)
+	}
+}
+
+func TestWatchSlowConsumer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c   = New[int, int](WithWatchBuffer(2))
+		sub = c.Watch(0)
+	)
+	defer sub.Close()
+
+	for i := 0; i < 5; i++ {
+		c.Put(time.Minute, 0, i)
+	}
+
+	// The oldest events are dropped so the latest value is always seen
+	var expect = []Event[int, int]{
+		{EventPut, 0, 3},
+		{EventPut, 0, 4},
+	}
+
+	if events := drain(sub); !reflect.DeepEqual(events, expect) {
+		t.Errorf("Expected %v, got %v", expect, events)
+	}
+
+	if n := sub.Dropped(); n != 3 {
+		t.Errorf("Expected 3 dropped events, got %d", n)
+	}
+}
+
+func TestWatchClose(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c   = New[int, int]()
+		sub = c.Watch(0)
+		all = c.WatchAll()
+		w   = c.(*cache[int, int]).watchers
+	)
+
+	if err := sub.Close(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if err := sub.Close(); err != ErrClosed {
+		t.Errorf("Expected error %v, got %v", ErrClosed, err)
+	}
+
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Expected the events channel to be closed")
+	}
+
+	all.Close()
+
+	if len(w.keys) != 0 || len(w.all) != 0 || w.n.Load() != 0 {
+		t.Errorf("Expected no subscriptions left, got %d keys, %d all, %d open", len(w.keys), len(w.all), w.n.Load())
+	}
+
+	// Changes after Close must not panic on the closed channel
+	c.Put(time.Minute, 0, 0)
+}
+
+func TestWatchConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c    = New[int, int](WithShards(4), WithWatchBuffer(4))
+		sub  = c.WatchAll()
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+
+	go func() {
+		defer close(done)
+		for range sub.Events() {
+		}
+	}()
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				c.Put(time.Minute, g*1000+i, i)
+				c.Remove(g*1000 + i)
+			}
+		}(g)
+	}
+
+	// Closing while events are still being sent must not race with the senders
+	time.Sleep(time.Millisecond)
+	sub.Close()
+	<-done
+
+	wg.Wait()
+}
+
+func TestEventKindString(t *testing.T) {
+	t.Parallel()
+
+	for kind, expect := range map[EventKind]string{
+		EventPut:     "put",
+		EventRemove:  "remove",
+		EventExpire:  "expire",
+		EventEvict:   "evict",
+		EventKind(9): "unknown",
+	} {
+		if s := kind.String(); s != expect {
+			t.Errorf("Expected %s, got %s", expect, s)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+// Weigher returns the weight of an entry, usually an estimate of its size in bytes.
+type Weigher[I comparable, T any] func(I, T) int64
+
+// NewWeighted creates a cache that limits the total weight of its entries, as measured by the weigher,
+// evicting the least recently used entries once it is exceeded. Values weighing more than the whole
+// limit are not stored. Both Put and Get count as use.
+//
+// The limit applies to the cache as a whole, so it cannot be combined with WithShards.
+//
+//	c := cache.NewWeighted(64<<20, func(k string, v []byte) int64 {
+//		return int64(len(k) + len(v))
+//	})
+func NewWeighted[I comparable, T any](maxWeight int64, weigher Weigher[I, T], opts ...Option) Cache[I, T] {
+	if maxWeight <= 0 {
+		panic("expected a positive max weight")
+	}
+
+	if weigher == nil {
+		panic("expected a weigher")
+	}
+
+	return newCache[I, T](0, nil, maxWeight, weigher, opts)
+}
+
+// weigh returns the weight of the entry, zero without a weigher.
+func (s *shard[I, T]) weigh(i I, v T) int64 {
+	if s.weigher == nil {
+		return 0
+	}
+
+	return s.weigher(i, v)
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func byteWeigher(k string, v []byte) int64 {
+	return int64(len(v))
+}
+
+func TestWeightEvict(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewWeighted(10, byteWeigher)
+	)
+
+	c.Put(time.Minute, "a", make([]byte, 4))
+	c.Put(time.Minute, "b", make([]byte, 4))
+	c.Get("a")
+
+	// b is the least recently used entry
+	c.Put(time.Minute, "c", make([]byte, 4))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("Expected %s to be present", k)
+		}
+	}
+
+	if st := c.Stats(); st.Weight != 8 || st.Evictions != 1 {
+		t.Errorf("Expected a weight of 8 and 1 eviction, got %+v", st)
+	}
+}
+
+func TestWeightReplace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewWeighted(10, byteWeigher)
+	)
+
+	c.Put(time.Minute, "a", make([]byte, 2))
+	c.Put(time.Minute, "b", make([]byte, 2))
+	c.Put(time.Minute, "b", make([]byte, 8))
+
+	if st := c.Stats(); st.Weight != 10 || st.Size != 2 {
+		t.Errorf("Expected a weight of 10 over 2 entries, got %+v", st)
+	}
+
+	// Growing b pushes a out
+	c.Put(time.Minute, "b", make([]byte, 9))
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected a to be evicted")
+	}
+
+	c.Remove("b")
+
+	if st := c.Stats(); st.Weight != 0 {
+		t.Errorf("Expected a weight of 0, got %d", st.Weight)
+	}
+}
+
+func TestWeightReject(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewWeighted(10, byteWeigher)
+	)
+
+	c.Put(time.Minute, "a", make([]byte, 4))
+	c.Put(time.Minute, "b", make([]byte, 4))
+	c.Put(time.Minute, "b", make([]byte, 11))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected oversized value to be refused and the old value dropped")
+	}
+
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected a to not be evicted for a refused value")
+	}
+
+	if st := c.Stats(); st.Rejections != 1 || st.Weight != 4 {
+		t.Errorf("Expected 1 rejection and a weight of 4, got %+v", st)
+	}
+}
+
+func TestWeightShards(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c = NewWeighted(10, byteWeigher)
+	)
+
+	// Any value within the whole limit is stored
+	c.Put(time.Minute, "a", make([]byte, 10))
+
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected a value as heavy as the limit to be stored")
+	}
+
+	// Per shard limits would refuse values lighter than the whole limit
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+
+	NewWeighted(10, byteWeigher, WithShards(2))
+}
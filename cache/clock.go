@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time to a cache.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the time from time.Now.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a clock that only moves when told to, meant for tests.
+//
+//	clock := cache.NewFakeClock(time.Now())
+//	c := cache.New[string, int](cache.WithClock(clock))
+//	c.Put(time.Minute, "a", 1)
+//	clock.Advance(time.Minute + 1)
+type FakeClock struct {
+	t  time.Time
+	mu sync.Mutex
+}
+
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{t: t}
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.t
+}
+
+// Advance moves the clock forward by the given duration.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.t = f.t.Add(d)
+	f.mu.Unlock()
+}
+
+// Set moves the clock to the given time.
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	f.t = t
+	f.mu.Unlock()
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = NewFakeClock(start)
+	)
+
+	clock.Advance(time.Hour)
+
+	if now := clock.Now(); !now.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected %v, got %v", start.Add(time.Hour), now)
+	}
+
+	clock.Set(start)
+
+	if now := clock.Now(); !now.Equal(start) {
+		t.Errorf("Expected %v, got %v", start, now)
+	}
+}
+
+func TestClockGet(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.Put(time.Minute, 0, 1337)
+
+	clock.Advance(time.Minute)
+
+	if v, ok := c.Get(0); !ok {
+		t.Error("Expected true, got false")
+	} else if v != 1337 {
+		t.Errorf("Expected 1337, got %d", v)
+	}
+
+	clock.Advance(time.Nanosecond)
+
+	if _, ok := c.Get(0); ok {
+		t.Error("Expected false, got true")
+	}
+}
+
+func TestClockRemove(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.Put(time.Minute, 0, 1337)
+	c.Put(time.Minute, 1, 1337)
+
+	if _, ok := c.Remove(0); !ok {
+		t.Error("Expected true, got false")
+	}
+
+	clock.Advance(time.Hour)
+
+	if _, ok := c.Remove(1); ok {
+		t.Error("Expected expired entry to not be returned by Remove")
+	}
+}
+
+func TestClockClean(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		c     = New[int, int](WithClock(clock))
+	)
+
+	c.Put(time.Minute, 0, 1337)
+	c.Put(time.Hour, 1, 1337)
+	c.Put(NoExpiration, 2, 1337)
+
+	clock.Advance(30 * time.Minute)
+	c.Clean()
+
+	if n := len(c.(*cache[int, int]).shards[0].mp); n != 2 {
+		t.Errorf("Expected 2 entries, got %d", n)
+	}
+
+	clock.Advance(time.Hour)
+	c.Clean()
+
+	if n := len(c.(*cache[int, int]).shards[0].mp); n != 1 {
+		t.Errorf("Expected 1 entry, got %d", n)
+	}
+}
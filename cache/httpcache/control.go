@@ -0,0 +1,98 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl holds the Cache-Control directives relevant to a shared cache.
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	private bool
+	public  bool
+
+	// Negative when the directive is absent
+	maxAge  time.Duration
+	sMaxAge time.Duration
+}
+
+func parseCacheControl(h http.Header) cacheControl {
+	var cc = cacheControl{maxAge: -1, sMaxAge: -1}
+
+	for _, v := range h.Values("Cache-Control") {
+		for _, dir := range strings.Split(v, ",") {
+			var name, arg, _ = strings.Cut(strings.TrimSpace(dir), "=")
+
+			switch strings.ToLower(name) {
+			case "no-store":
+				cc.noStore = true
+			case "no-cache":
+				cc.noCache = true
+			case "private":
+				cc.private = true
+			case "public":
+				cc.public = true
+			case "max-age":
+				cc.maxAge = parseSeconds(arg)
+			case "s-maxage":
+				cc.sMaxAge = parseSeconds(arg)
+			}
+		}
+	}
+
+	return cc
+}
+
+// parseSeconds parses a delta-seconds argument, returning -1 when it is invalid.
+func parseSeconds(arg string) time.Duration {
+	n, err := strconv.ParseInt(strings.Trim(arg, `"`), 10, 64)
+	if err != nil || n < 0 {
+		return -1
+	}
+
+	return time.Duration(n) * time.Second
+}
+
+// notModified reports whether the conditional headers of the request match the response,
+// If-None-Match takes precedence over If-Modified-Since as required by RFC 9110.
+func notModified(r *http.Request, resp *Response) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		var etag = resp.Header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || weakMatch(tag, etag) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+
+		modified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+		if err != nil {
+			return false
+		}
+
+		return !modified.After(since)
+	}
+
+	return false
+}
+
+// weakMatch compares two entity tags ignoring the weak indicator.
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
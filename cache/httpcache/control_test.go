@@ -0,0 +1,40 @@
+package httpcache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		header []string
+		want   cacheControl
+	}{
+		{nil, cacheControl{maxAge: -1, sMaxAge: -1}},
+		{[]string{"max-age=60"}, cacheControl{maxAge: time.Minute, sMaxAge: -1}},
+		{[]string{`Max-Age="60", s-maxage=5`}, cacheControl{maxAge: time.Minute, sMaxAge: 5 * time.Second}},
+		{[]string{"max-age=-1", "s-maxage=x"}, cacheControl{maxAge: -1, sMaxAge: -1}},
+		{[]string{"no-store, no-cache", "private, public"}, cacheControl{noStore: true, noCache: true, private: true, public: true, maxAge: -1, sMaxAge: -1}},
+	}
+
+	for _, test := range tests {
+		if got := parseCacheControl(http.Header{"Cache-Control": test.header}); got != test.want {
+			t.Errorf("Expected %+v for %q, got %+v", test.want, test.header, got)
+		}
+	}
+}
+
+func TestWeakMatch(t *testing.T) {
+	t.Parallel()
+
+	if !weakMatch(`W/"a"`, `"a"`) {
+		t.Error("Expected true, got false")
+	}
+
+	if weakMatch(`"a"`, `"b"`) {
+		t.Error("Expected false, got true")
+	}
+}
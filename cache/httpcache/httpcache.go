@@ -0,0 +1,284 @@
+package httpcache
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NublyBR/go-utils/cache"
+)
+
+// Response is a complete response as stored in the cache.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+
+	// When the response was generated, used for the Age header
+	Stored time.Time
+}
+
+// Statuses cacheable by default, see RFC 9110 section 15.1
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+type call struct {
+	wg   sync.WaitGroup
+	resp Response
+	ok   bool
+}
+
+type handler struct {
+	c    cache.Cache[string, Response]
+	next http.Handler
+
+	vary   []string
+	maxAge time.Duration
+
+	calls map[string]*call
+	mu    sync.Mutex
+}
+
+// New returns a middleware that caches the responses to GET and HEAD requests, keyed on the method,
+// host, request URI and the headers given with WithVary.
+//
+// Responses are stored for their s-maxage or max-age, and never when marked no-store, no-cache or
+// private, when setting cookies, or when answering a request with an Authorization header unless
+// marked public or s-maxage. Requests marked no-cache skip the lookup and no-store skip the cache.
+// Conditional requests are answered with 304 Not Modified from the ETag and Last-Modified headers
+// of the stored response. Concurrent misses for the same key run the handler once.
+//
+// Any other method passes through and removes the cached responses for its URI. Responses are
+// buffered in full before being sent.
+//
+//	c := cache.NewLRU[string, httpcache.Response](1000)
+//	http.ListenAndServe(":8080", httpcache.New(c)(mux))
+func New(c cache.Cache[string, Response], opts ...Option) func(http.Handler) http.Handler {
+	var o = buildOptions(opts)
+
+	return func(next http.Handler) http.Handler {
+		return &handler{
+			c:    c,
+			next: next,
+
+			vary:   o.vary,
+			maxAge: o.maxAge,
+
+			calls: make(map[string]*call),
+		}
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.next.ServeHTTP(w, r)
+		h.c.InvalidateTag(resource(r))
+		return
+	}
+
+	var cc = parseCacheControl(r.Header)
+	if cc.noStore {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	var key = h.key(r)
+
+	if !cc.noCache {
+		if resp, ok := h.c.Get(key); ok {
+			serve(w, r, &resp, true)
+			return
+		}
+	}
+
+	resp, shared := h.load(key, r)
+	serve(w, r, &resp, shared)
+}
+
+// load runs the handler for the request and caches the response if allowed, requests waiting
+// on another one for the same key share its response, or run the handler themselves if it
+// could not be cached.
+func (h *handler) load(key string, r *http.Request) (Response, bool) {
+	h.mu.Lock()
+	if cl, ok := h.calls[key]; ok {
+		h.mu.Unlock()
+
+		cl.wg.Wait()
+		if cl.ok {
+			return cl.resp, true
+		}
+
+		return h.record(r), false
+	}
+
+	var cl = &call{}
+	cl.wg.Add(1)
+	h.calls[key] = cl
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.calls, key)
+		h.mu.Unlock()
+
+		cl.wg.Done()
+	}()
+
+	cl.resp = h.record(r)
+
+	if d, ok := h.lifetime(r, &cl.resp); ok {
+		h.c.PutTagged(d, key, cl.resp, resource(r))
+		cl.ok = true
+	}
+
+	return cl.resp, false
+}
+
+// record runs the handler without the conditional headers of the request, so the full response
+// can be stored, and returns the response it wrote.
+func (h *handler) record(r *http.Request) Response {
+	var (
+		req = r.Clone(r.Context())
+		rec = &recorder{header: make(http.Header)}
+	)
+
+	req.Header.Del("If-None-Match")
+	req.Header.Del("If-Modified-Since")
+
+	h.next.ServeHTTP(rec, req)
+
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	return Response{
+		Status: rec.status,
+		Header: rec.header,
+		Body:   rec.body.Bytes(),
+
+		Stored: time.Now(),
+	}
+}
+
+// lifetime returns how long the response to the request can be cached for.
+func (h *handler) lifetime(r *http.Request, resp *Response) (time.Duration, bool) {
+	if !cacheableStatus[resp.Status] || resp.Header.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+
+	var cc = parseCacheControl(resp.Header)
+	if cc.noStore || cc.noCache || cc.private {
+		return 0, false
+	}
+
+	if r.Header.Get("Authorization") != "" && !cc.public && cc.sMaxAge < 0 {
+		return 0, false
+	}
+
+	// The key only tells apart the headers given with WithVary
+	for _, v := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" && !slices.Contains(h.vary, name) {
+				return 0, false
+			}
+		}
+	}
+
+	var d time.Duration
+	switch {
+	case cc.sMaxAge >= 0:
+		d = cc.sMaxAge
+	case cc.maxAge >= 0:
+		d = cc.maxAge
+	default:
+		d = h.maxAge
+	}
+
+	return d, d > 0
+}
+
+func (h *handler) key(r *http.Request) string {
+	var b strings.Builder
+
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(resource(r))
+
+	for _, name := range h.vary {
+		b.WriteString("\n" + name + ": " + strings.Join(r.Header.Values(name), ", "))
+	}
+
+	return b.String()
+}
+
+// resource identifies the target of the request, every response cached for it is tagged with it.
+func resource(r *http.Request) string {
+	return r.Host + r.URL.RequestURI()
+}
+
+// serve writes the response, or 304 Not Modified if the request is conditional and matches it.
+func serve(w http.ResponseWriter, r *http.Request, resp *Response, cached bool) {
+	var hdr = w.Header()
+	for k, v := range resp.Header {
+		hdr[k] = slices.Clone(v)
+	}
+
+	if cached {
+		hdr.Set("Age", strconv.FormatInt(int64(time.Since(resp.Stored)/time.Second), 10))
+	}
+
+	if resp.Status == http.StatusOK && notModified(r, resp) {
+		hdr.Del("Content-Type")
+		hdr.Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(resp.Status)
+
+	if r.Method != http.MethodHead {
+		w.Write(resp.Body)
+	}
+}
+
+// recorder buffers the response written by the handler.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	// Informational responses are not part of the final response
+	if rec.status == 0 && status >= 200 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	return rec.body.Write(b)
+}
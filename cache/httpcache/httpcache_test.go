@@ -0,0 +1,273 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NublyBR/go-utils/cache"
+)
+
+// origin counts the requests it serves and answers with the given Cache-Control header.
+type origin struct {
+	calls   atomic.Int64
+	control string
+	header  http.Header
+}
+
+func (o *origin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var n = o.calls.Add(1)
+
+	for k, v := range o.header {
+		w.Header()[k] = v
+	}
+
+	if o.control != "" {
+		w.Header().Set("Cache-Control", o.control)
+	}
+
+	w.Write([]byte(r.Method + " " + r.URL.Path + " " + r.Header.Get("Accept-Language") + " " + string(rune('0'+n))))
+}
+
+func do(h http.Handler, method, url string, header http.Header) *httptest.ResponseRecorder {
+	var (
+		req = httptest.NewRequest(method, url, nil)
+		rec = httptest.NewRecorder()
+	)
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = cache.NewFakeClock(time.Now())
+		o     = &origin{control: "max-age=60"}
+		h     = New(cache.New[string, Response](cache.WithClock(clock)))(o)
+	)
+
+	var first = do(h, "GET", "/a", nil)
+	if first.Code != http.StatusOK || first.Body.String() != "GET /a  1" {
+		t.Errorf("Expected 200 GET /a  1, got %d %s", first.Code, first.Body)
+	}
+
+	var second = do(h, "GET", "/a", nil)
+	if second.Body.String() != "GET /a  1" {
+		t.Errorf("Expected cached response, got %s", second.Body)
+	}
+
+	if second.Header().Get("Age") == "" {
+		t.Error("Expected an Age header on the cached response")
+	}
+
+	if head := do(h, "HEAD", "/a", nil); head.Body.Len() != 0 {
+		t.Errorf("Expected no body for HEAD, got %s", head.Body)
+	}
+
+	do(h, "GET", "/b", nil)
+
+	if n := o.calls.Load(); n != 3 {
+		t.Errorf("Expected 3 calls, got %d", n)
+	}
+
+	clock.Advance(61 * time.Second)
+
+	if rec := do(h, "GET", "/a", nil); rec.Body.String() != "GET /a  4" {
+		t.Errorf("Expected expired response to be reloaded, got %s", rec.Body)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		o = &origin{control: "max-age=60"}
+		h = New(cache.New[string, Response](), WithVary("Accept-Language"))(o)
+
+		en = http.Header{"Accept-Language": {"en"}}
+		pt = http.Header{"Accept-Language": {"pt"}}
+	)
+
+	do(h, "GET", "/a", en)
+	do(h, "GET", "/a", pt)
+	do(h, "GET", "/b", nil)
+	do(h, "POST", "/a", nil)
+
+	do(h, "GET", "/a", en)
+	do(h, "GET", "/a", pt)
+	do(h, "GET", "/b", nil)
+
+	if n := o.calls.Load(); n != 6 {
+		t.Errorf("Expected 6 calls, got %d", n)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		control string
+		header  http.Header
+		req     http.Header
+		opts    []Option
+		cached  bool
+	}{
+		{control: "max-age=60", cached: true},
+		{control: "s-maxage=60, max-age=0", cached: true},
+		{control: "max-age=60, s-maxage=0", cached: false},
+		{control: "max-age=0", cached: false},
+		{control: "no-store, max-age=60", cached: false},
+		{control: "no-cache, max-age=60", cached: false},
+		{control: "private, max-age=60", cached: false},
+		{control: "", cached: false},
+		{control: "", opts: []Option{WithDefaultMaxAge(time.Minute)}, cached: true},
+		{control: "max-age=60", header: http.Header{"Set-Cookie": {"a=b"}}, cached: false},
+		{control: "max-age=60", header: http.Header{"Vary": {"Accept-Language"}}, cached: false},
+		{control: "max-age=60", header: http.Header{"Vary": {"accept-language"}}, opts: []Option{WithVary("Accept-Language")}, cached: true},
+		{control: "max-age=60", header: http.Header{"Vary": {"*"}}, cached: false},
+		{control: "max-age=60", req: http.Header{"Authorization": {"x"}}, cached: false},
+		{control: "public, max-age=60", req: http.Header{"Authorization": {"x"}}, cached: true},
+		{control: "max-age=60", req: http.Header{"Cache-Control": {"no-store"}}, cached: false},
+		{control: "max-age=60", req: http.Header{"Cache-Control": {"no-cache"}}, cached: false},
+	}
+
+	for _, test := range tests {
+		var (
+			o = &origin{control: test.control, header: test.header}
+			h = New(cache.New[string, Response](), test.opts...)(o)
+		)
+
+		do(h, "GET", "/", test.req)
+		do(h, "GET", "/", test.req)
+
+		if cached := o.calls.Load() == 1; cached != test.cached {
+			t.Errorf("Expected cached %v for %q %v %v, got %v", test.cached, test.control, test.header, test.req, cached)
+		}
+	}
+}
+
+func TestCacheConditional(t *testing.T) {
+	t.Parallel()
+
+	var (
+		modified = time.Now().Add(-time.Hour).UTC()
+		o        = &origin{control: "max-age=60", header: http.Header{
+			"Etag":          {`"v1"`},
+			"Last-Modified": {modified.Format(http.TimeFormat)},
+			"Content-Type":  {"text/plain"},
+		}}
+		h = New(cache.New[string, Response]())(o)
+	)
+
+	// The handler never sees the conditional headers, so the full response is cached
+	if rec := do(h, "GET", "/", http.Header{"If-None-Match": {`"v1"`}}); rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %d", rec.Code)
+	} else if rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("Expected no body or content type, got %q %q", rec.Body, rec.Header().Get("Content-Type"))
+	} else if rec.Header().Get("Etag") != `"v1"` {
+		t.Errorf(`Expected ETag "v1", got %s`, rec.Header().Get("Etag"))
+	}
+
+	var tests = []struct {
+		req  http.Header
+		code int
+	}{
+		{http.Header{"If-None-Match": {`"v0", W/"v1"`}}, http.StatusNotModified},
+		{http.Header{"If-None-Match": {"*"}}, http.StatusNotModified},
+		{http.Header{"If-None-Match": {`"v0"`}}, http.StatusOK},
+		{http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}}, http.StatusNotModified},
+		{http.Header{"If-Modified-Since": {modified.Add(-time.Minute).Format(http.TimeFormat)}}, http.StatusOK},
+		{http.Header{"If-None-Match": {`"v0"`}, "If-Modified-Since": {modified.Format(http.TimeFormat)}}, http.StatusOK},
+		{nil, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		if rec := do(h, "GET", "/", test.req); rec.Code != test.code {
+			t.Errorf("Expected %d for %v, got %d", test.code, test.req, rec.Code)
+		}
+	}
+
+	if n := o.calls.Load(); n != 1 {
+		t.Errorf("Expected 1 call, got %d", n)
+	}
+}
+
+func TestCacheCoalesce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int64
+		release = make(chan struct{})
+		h       = New(cache.New[string, Response]())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			<-release
+
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Write([]byte("ok"))
+		}))
+
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if rec := do(h, "GET", "/", nil); rec.Body.String() != "ok" {
+				t.Errorf("Expected ok, got %s", rec.Body)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 call, got %d", n)
+	}
+}
+
+func TestCacheCoalesceUncacheable(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int64
+		release = make(chan struct{})
+		h       = New(cache.New[string, Response]())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			<-release
+
+			w.Header().Set("Cache-Control", "private")
+			w.Write([]byte("ok"))
+		}))
+
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			do(h, "GET", "/", nil)
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+
+	// Private responses are never shared between requests
+	if n := calls.Load(); n != 4 {
+		t.Errorf("Expected 4 calls, got %d", n)
+	}
+}
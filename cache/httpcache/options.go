@@ -0,0 +1,47 @@
+package httpcache
+
+import (
+	"net/http"
+	"time"
+)
+
+type Option func(*options)
+
+type options struct {
+	vary   []string
+	maxAge time.Duration
+}
+
+func buildOptions(opts []Option) *options {
+	var o = &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithVary adds request headers to the cache key, so each combination of their values is cached
+// separately. Responses with a Vary header naming any other request header are not cached.
+//
+//	mw := httpcache.New(c, httpcache.WithVary("Accept-Encoding", "Accept-Language"))
+func WithVary(headers ...string) Option {
+	return func(o *options) {
+		for _, h := range headers {
+			o.vary = append(o.vary, http.CanonicalHeaderKey(h))
+		}
+	}
+}
+
+// WithDefaultMaxAge caches responses without an explicit max-age or s-maxage directive for the
+// given duration, by default such responses are not cached.
+func WithDefaultMaxAge(d time.Duration) Option {
+	if d <= 0 {
+		panic("expected a positive default max age")
+	}
+
+	return func(o *options) {
+		o.maxAge = d
+	}
+}
@@ -0,0 +1,483 @@
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NublyBR/go-utils/cache"
+)
+
+const (
+	// Exptimes up to 30 days are relative, larger ones are unix timestamps, as in memcached
+	maxRelativeExptime = 60 * 60 * 24 * 30
+	// Values are stored with their client flags in front, as 4 big endian bytes
+	flagsLength = 4
+)
+
+var (
+	errLineTooLong = errors.New("line too long")
+)
+
+// readLine reads a command line without its line ending. Lines longer than maxLineLength are
+// read up to their end and dropped, returning errLineTooLong.
+func readLine(r *bufio.Reader) (string, error) {
+	var (
+		line    []byte
+		tooLong bool
+	)
+
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if err != nil && err != bufio.ErrBufferFull {
+			return "", err
+		}
+
+		if len(line)+len(chunk) > maxLineLength+2 {
+			tooLong = true
+		} else if !tooLong {
+			line = append(line, chunk...)
+		}
+
+		if err == nil {
+			break
+		}
+	}
+
+	if tooLong {
+		return "", errLineTooLong
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// expiration converts a memcached exptime to a cache duration, also reporting whether
+// the item is already expired.
+func expiration(exptime int64) (time.Duration, bool) {
+	switch {
+	case exptime == 0:
+		return cache.NoExpiration, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= maxRelativeExptime:
+		return time.Duration(exptime) * time.Second, false
+	default:
+		var d = time.Until(time.Unix(exptime, 0))
+		return d, d <= 0
+	}
+}
+
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > maxKeyLength {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decode splits a stored value into its client flags and data, values too short to have
+// been written by the server are reported as missing.
+func decode(v []byte) (uint32, []byte, bool) {
+	if len(v) < flagsLength {
+		return 0, nil, false
+	}
+
+	return binary.BigEndian.Uint32(v), v[flagsLength:], true
+}
+
+// encode returns the stored form of the data with its client flags.
+func encode(flags uint32, data []byte) []byte {
+	var v = make([]byte, flagsLength+len(data))
+	binary.BigEndian.PutUint32(v, flags)
+	copy(v[flagsLength:], data)
+	return v
+}
+
+// casUnique returns the cas unique value reported for a value.
+func casUnique(v []byte) uint64 {
+	var h = fnv.New64a()
+	h.Write(v)
+	return h.Sum64()
+}
+
+// noreply strips a trailing noreply argument, reporting whether it was present.
+func noreply(args []string) ([]string, bool) {
+	if len(args) > 0 && args[len(args)-1] == "noreply" {
+		return args[:len(args)-1], true
+	}
+
+	return args, false
+}
+
+// handle runs a single command line, reading its data block from r if it has one,
+// and returns true once the connection should be closed.
+func (s *server) handle(line string, r *bufio.Reader, w *bufio.Writer) bool {
+	var fields = strings.Fields(line)
+	if len(fields) == 0 {
+		w.WriteString("ERROR\r\n")
+		return false
+	}
+
+	var cmd, args = fields[0], fields[1:]
+
+	switch cmd {
+	case "get", "gets":
+		s.get(args, cmd == "gets", w)
+
+	case "set", "add", "replace":
+		s.cmdSet.Add(1)
+		return s.store(cmd, args, r, w)
+
+	case "delete":
+		s.delete(args, w)
+
+	case "touch":
+		s.cmdTouch.Add(1)
+		s.touch(args, w)
+
+	case "incr", "decr":
+		s.incr(cmd == "incr", args, w)
+
+	case "flush_all":
+		s.flushAll(args, w)
+
+	case "stats":
+		s.stats(args, w)
+
+	case "version":
+		w.WriteString("VERSION go-utils\r\n")
+
+	case "quit":
+		return true
+
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+
+	return false
+}
+
+func (s *server) get(keys []string, cas bool, w *bufio.Writer) {
+	if len(keys) == 0 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	// As in memcached, every key requested counts as a get
+	s.cmdGet.Add(uint64(len(keys)))
+
+	for _, key := range keys {
+		v, ok := s.c.Get(key)
+		if !ok {
+			continue
+		}
+
+		flags, data, ok := decode(v)
+		if !ok {
+			continue
+		}
+
+		w.WriteString("VALUE " + key + " " + strconv.FormatUint(uint64(flags), 10) + " " + strconv.Itoa(len(data)))
+		if cas {
+			w.WriteString(" " + strconv.FormatUint(casUnique(v), 10))
+		}
+		w.WriteString("\r\n")
+		w.Write(data)
+		w.WriteString("\r\n")
+	}
+
+	w.WriteString("END\r\n")
+}
+
+// store handles set, add and replace:
+//
+//	<command> <key> <flags> <exptime> <bytes> [noreply]\r\n<data>\r\n
+func (s *server) store(cmd string, args []string, r *bufio.Reader, w *bufio.Writer) bool {
+	args, quiet := noreply(args)
+
+	if len(args) != 4 {
+		w.WriteString("ERROR\r\n")
+		return false
+	}
+
+	var (
+		key                 = args[0]
+		flags, errFlags     = strconv.ParseUint(args[1], 10, 32)
+		exptime, errExptime = strconv.ParseInt(args[2], 10, 64)
+		length, errLength   = strconv.Atoi(args[3])
+		validLength         = errLength == nil && length >= 0
+		badFormat           = !validKey(key) || errFlags != nil || errExptime != nil
+		d, expired          = expiration(exptime)
+	)
+
+	if !validLength {
+		// The data block cannot be skipped without its length
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return true
+	}
+
+	if length > maxValueLength {
+		if _, err := r.Discard(length + 2); err != nil {
+			return true
+		}
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+		return false
+	}
+
+	// The data is read right after the room for the flags, saving a copy
+	var data = make([]byte, flagsLength+length+2)
+	if _, err := io.ReadFull(r, data[flagsLength:]); err != nil {
+		return true
+	}
+
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return true
+	}
+	data = data[:flagsLength+length]
+
+	if badFormat {
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return false
+	}
+
+	binary.BigEndian.PutUint32(data, uint32(flags))
+
+	var stored bool
+
+	switch cmd {
+	case "set":
+		if expired {
+			s.c.Remove(key)
+		} else {
+			s.c.Put(d, key, data)
+		}
+		stored = true
+
+	case "add":
+		if expired {
+			_, ok := s.c.Get(key)
+			stored = !ok
+		} else {
+			_, loaded := s.c.PutIfAbsent(d, key, data)
+			stored = !loaded
+		}
+
+	case "replace":
+		s.c.Update(key, d, func(old []byte, ok bool) ([]byte, bool) {
+			stored = ok
+			return data, ok && !expired
+		})
+	}
+
+	if !quiet {
+		if stored {
+			w.WriteString("STORED\r\n")
+		} else {
+			w.WriteString("NOT_STORED\r\n")
+		}
+	}
+
+	return false
+}
+
+// delete handles delete <key> [0] [noreply], the legacy 0 time argument is accepted.
+func (s *server) delete(args []string, w *bufio.Writer) {
+	args, quiet := noreply(args)
+
+	if len(args) == 2 && args[1] == "0" {
+		args = args[:1]
+	}
+
+	if len(args) != 1 {
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return
+	}
+
+	_, ok := s.c.Remove(args[0])
+
+	if !quiet {
+		if ok {
+			w.WriteString("DELETED\r\n")
+		} else {
+			w.WriteString("NOT_FOUND\r\n")
+		}
+	}
+}
+
+// touch handles touch <key> <exptime> [noreply].
+func (s *server) touch(args []string, w *bufio.Writer) {
+	args, quiet := noreply(args)
+
+	if len(args) != 2 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	exptime, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		w.WriteString("CLIENT_ERROR invalid exptime argument\r\n")
+		return
+	}
+
+	var (
+		d, expired = expiration(exptime)
+		found      bool
+	)
+
+	if expired {
+		_, found = s.c.Remove(args[0])
+	} else {
+		found = s.c.SetExpiration(args[0], d)
+	}
+
+	if !quiet {
+		if found {
+			w.WriteString("TOUCHED\r\n")
+		} else {
+			w.WriteString("NOT_FOUND\r\n")
+		}
+	}
+}
+
+// incr handles incr and decr <key> <value> [noreply], values are unsigned 64 bit integers,
+// incr wraps around and decr stops at 0 as in memcached.
+func (s *server) incr(incr bool, args []string, w *bufio.Writer) {
+	args, quiet := noreply(args)
+
+	if len(args) != 2 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	delta, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		w.WriteString("CLIENT_ERROR invalid numeric delta argument\r\n")
+		return
+	}
+
+	var (
+		found, numeric bool
+		result         uint64
+	)
+
+	s.c.Compute(args[0], cache.KeepExpiration, func(v []byte, ok bool) ([]byte, cache.UpdateAction) {
+		flags, data, ok := decode(v)
+		if found = ok; !ok {
+			return nil, cache.UpdateKeep
+		}
+
+		n, err := strconv.ParseUint(string(data), 10, 64)
+		if numeric = err == nil; !numeric {
+			return v, cache.UpdateKeep
+		}
+
+		if incr {
+			result = n + delta
+		} else if delta > n {
+			result = 0
+		} else {
+			result = n - delta
+		}
+
+		return encode(flags, []byte(strconv.FormatUint(result, 10))), cache.UpdateStore
+	})
+
+	if quiet {
+		return
+	}
+
+	switch {
+	case !found:
+		w.WriteString("NOT_FOUND\r\n")
+	case !numeric:
+		w.WriteString("CLIENT_ERROR cannot increment or decrement non-numeric value\r\n")
+	default:
+		w.WriteString(strconv.FormatUint(result, 10) + "\r\n")
+	}
+}
+
+// flushAll handles flush_all [delay] [noreply], the delay is not supported and must be 0 if given.
+func (s *server) flushAll(args []string, w *bufio.Writer) {
+	args, quiet := noreply(args)
+
+	if len(args) > 1 || (len(args) == 1 && args[0] != "0") {
+		w.WriteString("CLIENT_ERROR delayed flush_all not supported\r\n")
+		return
+	}
+
+	for _, key := range s.c.Keys() {
+		s.c.Remove(key)
+	}
+
+	if !quiet {
+		w.WriteString("OK\r\n")
+	}
+}
+
+// stats handles stats and stats reset.
+func (s *server) stats(args []string, w *bufio.Writer) {
+	if len(args) == 1 && args[0] == "reset" {
+		s.c.ResetStats()
+		w.WriteString("RESET\r\n")
+		return
+	}
+
+	if len(args) != 0 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	var st = s.c.Stats()
+
+	s.mu.Lock()
+	var currConns = len(s.conns)
+	s.mu.Unlock()
+
+	var stat = func(name string, value any) {
+		w.WriteString("STAT " + name + " ")
+		switch v := value.(type) {
+		case int:
+			w.WriteString(strconv.Itoa(v))
+		case int64:
+			w.WriteString(strconv.FormatInt(v, 10))
+		case uint64:
+			w.WriteString(strconv.FormatUint(v, 10))
+		case string:
+			w.WriteString(v)
+		}
+		w.WriteString("\r\n")
+	}
+
+	stat("pid", os.Getpid())
+	stat("uptime", int64(time.Since(s.started)/time.Second))
+	stat("time", time.Now().Unix())
+	stat("version", "go-utils")
+	stat("curr_connections", currConns)
+	stat("total_connections", s.totalConns.Load())
+	stat("cmd_get", s.cmdGet.Load())
+	stat("cmd_set", s.cmdSet.Load())
+	stat("cmd_touch", s.cmdTouch.Load())
+	stat("get_hits", st.Hits)
+	// Cached errors read back as misses over memcached
+	stat("get_misses", st.Misses+st.ErrorHits)
+	// bytes is left out, the cache only knows the size of its entries through a weigher
+	stat("curr_items", st.Size)
+	stat("evictions", st.Evictions)
+	stat("reclaimed", st.Expirations)
+
+	w.WriteString("END\r\n")
+}
@@ -0,0 +1,199 @@
+package memcached
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NublyBR/go-utils/cache"
+)
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	cl.send("get a\r\n", "END\r\n")
+	cl.send("replace a 0 0 1\r\nx\r\n", "NOT_STORED\r\n")
+	cl.send("add a 0 0 5\r\nhello\r\n", "STORED\r\n")
+	cl.send("add a 0 0 1\r\nx\r\n", "NOT_STORED\r\n")
+	cl.send("get a\r\n", "VALUE a 0 5\r\nhello\r\nEND\r\n")
+	cl.send("replace a 0 0 5\r\nworld\r\n", "STORED\r\n")
+	cl.send("set b 0 0 0\r\n\r\n", "STORED\r\n")
+	cl.send("get a b c\r\n", "VALUE a 0 5\r\nworld\r\nVALUE b 0 0\r\n\r\nEND\r\n")
+
+	var cas = strconv.FormatUint(casUnique(encode(0, []byte("world"))), 10)
+	cl.send("gets a\r\n", "VALUE a 0 5 "+cas+"\r\nworld\r\nEND\r\n")
+
+	cl.send("delete a\r\n", "DELETED\r\n")
+	cl.send("delete a\r\n", "NOT_FOUND\r\n")
+	cl.send("delete b noreply\r\nget b\r\n", "END\r\n")
+}
+
+func TestStorageErrors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	cl.send("set "+strings.Repeat("a", maxKeyLength+1)+" 0 0 1\r\nx\r\n", "CLIENT_ERROR bad command line format\r\n")
+	cl.send("set a 0 0 "+strconv.Itoa(maxValueLength+1)+"\r\n"+strings.Repeat("x", maxValueLength+1)+"\r\n",
+		"SERVER_ERROR object too large for cache\r\n")
+	cl.send("get a\r\n", "END\r\n")
+	cl.send("set a 0 0\r\n", "ERROR\r\n")
+	cl.send("bogus\r\n", "ERROR\r\n")
+	cl.send("set a 0 0 1\r\nxyz\r\n", "CLIENT_ERROR bad data chunk\r\n")
+}
+
+func TestStorageFlags(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c     = cache.New[string, []byte]()
+		_, cl = serve(t, c)
+	)
+
+	// Flags are kept along with the value, through incr as well
+	cl.send("set a 42 0 1\r\nx\r\n", "STORED\r\n")
+	cl.send("get a\r\n", "VALUE a 42 1\r\nx\r\nEND\r\n")
+	cl.send("replace a 4294967295 0 1\r\ny\r\n", "STORED\r\n")
+	cl.send("get a\r\n", "VALUE a 4294967295 1\r\ny\r\nEND\r\n")
+	cl.send("set n 7 0 1\r\n1\r\n", "STORED\r\n")
+	cl.send("incr n 1\r\n", "2\r\n")
+	cl.send("get n\r\n", "VALUE n 7 1\r\n2\r\nEND\r\n")
+	cl.send("set a 4294967296 0 1\r\nx\r\n", "CLIENT_ERROR bad command line format\r\n")
+
+	// Values too short to hold the flags were not written by the server
+	c.Put(cache.NoExpiration, "b", []byte{1})
+	cl.send("get b\r\n", "END\r\n")
+}
+
+func TestExptime(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = cache.NewFakeClock(time.Now())
+		_, cl = serve(t, cache.New[string, []byte](cache.WithClock(clock)))
+	)
+
+	cl.send("set a 0 10 1\r\n1\r\n", "STORED\r\n")
+	cl.send("set b 0 0 1\r\n2\r\n", "STORED\r\n")
+	cl.send("set c 0 "+strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)+" 1\r\n3\r\n", "STORED\r\n")
+
+	clock.Advance(11 * time.Second)
+
+	cl.send("get a b c\r\n", "VALUE b 0 1\r\n2\r\nVALUE c 0 1\r\n3\r\nEND\r\n")
+
+	// Negative and past absolute exptimes expire the item right away
+	cl.send("set b 0 -1 1\r\n2\r\n", "STORED\r\n")
+	cl.send("set c 0 "+strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)+" 1\r\n3\r\n", "STORED\r\n")
+	cl.send("get b c\r\n", "END\r\n")
+
+	cl.send("touch a 10\r\n", "NOT_FOUND\r\n")
+	cl.send("set a 0 10 1\r\n1\r\n", "STORED\r\n")
+	cl.send("touch a 30\r\n", "TOUCHED\r\n")
+
+	clock.Advance(20 * time.Second)
+	cl.send("get a\r\n", "VALUE a 0 1\r\n1\r\nEND\r\n")
+
+	cl.send("touch a -1\r\n", "TOUCHED\r\n")
+	cl.send("get a\r\n", "END\r\n")
+}
+
+func TestIncrDecr(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock   = cache.NewFakeClock(time.Now())
+		c       = cache.New[string, []byte](cache.WithClock(clock))
+		_, cl   = serve(t, c)
+		evicted atomic.Int32
+	)
+
+	c.OnEvict(func(string, []byte, cache.EvictReason) {
+		evicted.Add(1)
+	})
+
+	cl.send("incr n 1\r\n", "NOT_FOUND\r\n")
+	cl.send("set n 0 10 2\r\n40\r\n", "STORED\r\n")
+	cl.send("incr n 2\r\n", "42\r\n")
+	cl.send("decr n 50\r\n", "0\r\n")
+	cl.send("set n 0 10 20\r\n18446744073709551615\r\n", "STORED\r\n")
+	cl.send("incr n 2\r\n", "1\r\n")
+	cl.send("incr n x\r\n", "CLIENT_ERROR invalid numeric delta argument\r\n")
+
+	// The expiry of the item is kept
+	clock.Advance(11 * time.Second)
+	cl.send("get n\r\n", "END\r\n")
+
+	cl.send("set s 0 0 3\r\nabc\r\n", "STORED\r\n")
+	var before = evicted.Load()
+	cl.send("incr s 1\r\n", "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n")
+	cl.send("get s\r\n", "VALUE s 0 3\r\nabc\r\nEND\r\n")
+
+	// A non-numeric value is left as it is instead of being written back
+	if n := evicted.Load(); n != before {
+		t.Errorf("Expected no evictions, got %d", n-before)
+	}
+}
+
+func TestFlushAllStats(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	cl.send("set a 0 0 1\r\n1\r\n", "STORED\r\n")
+	cl.send("set b 0 0 1\r\n2\r\n", "STORED\r\n")
+	cl.send("flush_all\r\n", "OK\r\n")
+	cl.send("get a b\r\n", "END\r\n")
+	cl.send("flush_all 10\r\n", "CLIENT_ERROR delayed flush_all not supported\r\n")
+
+	if _, err := cl.conn.Write([]byte("stats\r\n")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var stats = make(map[string]string)
+	for {
+		line, err := readLine(cl.r)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if line == "END" {
+			break
+		}
+
+		var fields = strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "STAT" {
+			t.Fatalf("Expected a STAT line, got %q", line)
+		}
+		stats[fields[1]] = fields[2]
+	}
+
+	for name, want := range map[string]string{
+		"cmd_get":          "2",
+		"cmd_set":          "2",
+		"get_misses":       "2",
+		"curr_items":       "0",
+		"curr_connections": "1",
+	} {
+		if stats[name] != want {
+			t.Errorf("Expected %s to be %s, got %q", name, want, stats[name])
+		}
+	}
+
+	// Without a byte count to report the stat is left out rather than always 0
+	if v, ok := stats["bytes"]; ok {
+		t.Errorf("Expected no bytes stat, got %q", v)
+	}
+
+	cl.send("stats reset\r\n", "RESET\r\n")
+	cl.send("stats bogus\r\n", "ERROR\r\n")
+}
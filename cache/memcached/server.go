@@ -0,0 +1,167 @@
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NublyBR/go-utils/cache"
+)
+
+var (
+	ErrClosed = errors.New("server closed")
+)
+
+const (
+	// Longest key accepted, as in memcached
+	maxKeyLength = 250
+	// Largest value accepted, as the memcached default item size
+	maxValueLength = 1 << 20
+	// Longest command line accepted, enough for a get of thousands of keys
+	maxLineLength = 1 << 20
+)
+
+type Server interface {
+	// Serve accepts connections on the listener until it fails or the server is closed,
+	// in which case ErrClosed is returned. It can be called for several listeners.
+	Serve(net.Listener) error
+	// Close closes every listener and connection of the server.
+	Close() error
+}
+
+type server struct {
+	c cache.Cache[string, []byte]
+
+	started time.Time
+
+	totalConns atomic.Uint64
+	cmdGet     atomic.Uint64
+	cmdSet     atomic.Uint64
+	cmdTouch   atomic.Uint64
+
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	isClosed  bool
+
+	mu sync.Mutex
+}
+
+// New creates a server speaking the memcached text protocol on top of the cache.
+//
+// Values are stored with the client flags in front of them, as 4 big endian bytes, so the cache
+// should only be written through the server. The cas unique value returned by gets is a hash of
+// the value since the cas command is not supported.
+//
+//	srv := memcached.New(cache.New[string, []byte]())
+//	l, _ := net.Listen("tcp", ":11211")
+//	go srv.Serve(l)
+func New(c cache.Cache[string, []byte]) Server {
+	return &server{
+		c: c,
+
+		started: time.Now(),
+
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+func (s *server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.isClosed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			var closed = s.isClosed
+			s.mu.Unlock()
+
+			if closed {
+				return ErrClosed
+			}
+
+			return err
+		}
+
+		s.mu.Lock()
+		if s.isClosed {
+			s.mu.Unlock()
+			conn.Close()
+			return ErrClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.totalConns.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isClosed {
+		return ErrClosed
+	}
+	s.isClosed = true
+
+	for l := range s.listeners {
+		l.Close()
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+
+	return nil
+}
+
+func (s *server) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	var (
+		r = bufio.NewReader(conn)
+		w = bufio.NewWriter(conn)
+	)
+
+	for {
+		line, err := readLine(r)
+		if err == errLineTooLong {
+			w.WriteString("CLIENT_ERROR line too long\r\n")
+		} else if err != nil {
+			return
+		} else if quit := s.handle(line, r, w); quit {
+			w.Flush()
+			return
+		}
+
+		// Flush once the pipelined commands already received were handled
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package memcached
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NublyBR/go-utils/cache"
+)
+
+// client is a minimal memcached text protocol client used by the tests.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// serve starts a server for the cache on a local listener and connects a client to it.
+func serve(t *testing.T, c cache.Cache[string, []byte]) (Server, *client) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var srv = New(c)
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	return srv, &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// send writes the raw request and checks that the server answers with the expected response.
+func (c *client) send(req, resp string) {
+	c.t.Helper()
+
+	if _, err := io.WriteString(c.conn, req); err != nil {
+		c.t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf = make([]byte, len(resp))
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		c.t.Fatalf("Expected %q, got %q (%v)", resp, buf, err)
+	}
+
+	if string(buf) != resp {
+		c.t.Errorf("Expected %q, got %q", resp, buf)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	cl.send("version\r\n", "VERSION go-utils\r\n")
+
+	if err := srv.Close(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if err := srv.Close(); err != ErrClosed {
+		t.Errorf("Expected error %v, got %v", ErrClosed, err)
+	}
+
+	if _, err := cl.r.ReadByte(); err == nil {
+		t.Error("Expected the connection to be closed")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer l.Close()
+
+	if err := srv.Serve(l); err != ErrClosed {
+		t.Errorf("Expected error %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestServerQuit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	cl.send("quit\r\n", "")
+
+	if _, err := cl.r.ReadByte(); err == nil {
+		t.Error("Expected the connection to be closed")
+	}
+}
+
+func TestServerPipelined(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	cl.send(
+		"set a 0 0 1\r\n1\r\nset b 0 0 1 noreply\r\n2\r\nget a b\r\n",
+		"STORED\r\nVALUE a 0 1\r\n1\r\nVALUE b 0 1\r\n2\r\nEND\r\n",
+	)
+}
+
+func TestServerLineTooLong(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_, cl = serve(t, cache.New[string, []byte]())
+	)
+
+	// Lines longer than the reader buffer are fine, a multi-get of long keys is a valid command
+	var keys = make([]string, 100)
+	for n := range keys {
+		keys[n] = strconv.Itoa(n) + strings.Repeat("a", maxKeyLength-3)
+	}
+
+	cl.send("set "+keys[99]+" 0 0 1\r\nx\r\n", "STORED\r\n")
+	cl.send("get "+strings.Join(keys, " ")+"\r\n", "VALUE "+keys[99]+" 0 1\r\nx\r\nEND\r\n")
+
+	// Lines over the limit are refused and the connection stays usable
+	cl.send("get "+strings.Repeat("a", maxLineLength)+"\r\n", "CLIENT_ERROR line too long\r\n")
+	cl.send("version\r\n", "VERSION go-utils\r\n")
+}
@@ -0,0 +1,131 @@
+package cache
+
+import "time"
+
+type Option func(*options)
+
+type options struct {
+	janitor time.Duration
+	shards  int
+	sliding bool
+	ttl     time.Duration
+	errTTL  time.Duration
+	codec   Codec
+	clock   Clock
+
+	// Size of the event buffer of each subscription, see Watch
+	watchBuffer int
+
+	tracking bool
+}
+
+func buildOptions(opts []Option) *options {
+	var o = &options{
+		shards: 1,
+		ttl:    NoExpiration,
+		codec:  GobCodec,
+		clock:  SystemClock,
+
+		watchBuffer: 16,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithJanitor starts a background goroutine that calls Clean at the given interval,
+// it runs until Close is called on the cache.
+//
+//	c := cache.New[string, int](cache.WithJanitor(time.Minute))
+//	defer c.Close()
+func WithJanitor(interval time.Duration) Option {
+	if interval <= 0 {
+		panic("expected a positive janitor interval")
+	}
+
+	return func(o *options) {
+		o.janitor = interval
+	}
+}
+
+// WithShards splits the keys across n independently locked shards to reduce lock contention,
+// bounded caches split their capacity evenly between the shards.
+func WithShards(n int) Option {
+	if n <= 0 {
+		panic("expected a positive shard count")
+	}
+
+	return func(o *options) {
+		o.shards = n
+	}
+}
+
+// WithSlidingExpiration makes every entry added with Put behave like one added with PutSliding,
+// the duration of an entry starts over every time it is read.
+func WithSlidingExpiration() Option {
+	return func(o *options) {
+		o.sliding = true
+	}
+}
+
+// WithDefaultExpiration sets the duration used for entries added with DefaultExpiration or Set,
+// it must be positive or NoExpiration.
+func WithDefaultExpiration(d time.Duration) Option {
+	if d <= 0 && d != NoExpiration {
+		panic("expected a positive default expiration or NoExpiration")
+	}
+
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// WithCodec sets the codec used by Save and Load, GobCodec is used by default.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithClock sets the clock used for every expiry decision, SystemClock is used by default.
+// The janitor interval is not affected and always follows the system time.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithErrorExpiration makes GetOrLoad cache the errors returned by its loader for the given duration,
+// as if added with PutError. Errors are not cached by default.
+func WithErrorExpiration(d time.Duration) Option {
+	if d <= 0 && d != NoExpiration {
+		panic("expected a positive error expiration or NoExpiration")
+	}
+
+	return func(o *options) {
+		o.errTTL = d
+	}
+}
+
+// WithWatchBuffer sets how many events each subscription returned by Watch and WatchAll buffers
+// before dropping the oldest ones, 16 by default.
+func WithWatchBuffer(n int) Option {
+	if n <= 0 {
+		panic("expected a positive watch buffer size")
+	}
+
+	return func(o *options) {
+		o.watchBuffer = n
+	}
+}
+
+// WithAccessTracking makes the cache count the reads of each entry and remember the last one,
+// as returned by GetEntry. Entries are not tracked by default to keep reads cheap.
+func WithAccessTracking() Option {
+	return func(o *options) {
+		o.tracking = true
+	}
+}
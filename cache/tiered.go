@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Tiered is a two level cache, an in-memory Cache in front of a slower Backend.
+type Tiered[I comparable, T any] interface {
+	// Get reads the first tier and falls back to the backend, filling the first tier with the value
+	// for the time it has left. Returns ErrNotFound if neither tier has the key.
+	Get(I) (T, error)
+	// Put writes the value to the backend and then to the first tier.
+	Put(time.Duration, I, T) error
+	// Remove removes the key from both tiers.
+	Remove(I) error
+}
+
+type tiered[I comparable, T any] struct {
+	l1 Cache[I, T]
+	l2 Backend
+
+	key   func(I) string
+	ttl   time.Duration
+	codec Codec
+	clock Clock
+}
+
+// NewTiered creates a two level cache, values are encoded with the codec set by WithCodec and keys
+// with the key function before reaching the backend, fmt.Sprint is used if it is nil.
+// DefaultExpiration resolves to the duration set by WithDefaultExpiration, as with New.
+//
+//	l2, err := cache.NewFileBackend("/var/cache/app")
+//	c := cache.NewTiered(cache.New[string, User](), l2, func(id string) string { return "user:" + id })
+func NewTiered[I comparable, T any](l1 Cache[I, T], l2 Backend, key func(I) string, opts ...Option) Tiered[I, T] {
+	var o = buildOptions(opts)
+
+	if key == nil {
+		key = func(i I) string { return fmt.Sprint(i) }
+	}
+
+	return &tiered[I, T]{
+		l1: l1,
+		l2: l2,
+
+		key:   key,
+		ttl:   o.ttl,
+		codec: o.codec,
+		clock: o.clock,
+	}
+}
+
+func (t *tiered[I, T]) Get(i I) (T, error) {
+	var zero T
+
+	if v, ok := t.l1.Get(i); ok {
+		return v, nil
+	}
+
+	b, exp, err := t.l2.Get(t.key(i))
+	if err != nil {
+		return zero, err
+	}
+
+	var v T
+	if err := t.codec.NewDecoder(bytes.NewReader(b)).Decode(&v); err != nil {
+		return zero, err
+	}
+
+	if exp.IsZero() {
+		t.l1.Put(NoExpiration, i, v)
+	} else if d := exp.Sub(t.clock.Now()); d > 0 {
+		t.l1.Put(d, i, v)
+	}
+
+	return v, nil
+}
+
+func (t *tiered[I, T]) Put(d time.Duration, i I, v T) error {
+	if d == DefaultExpiration {
+		d = t.ttl
+	}
+
+	var buf bytes.Buffer
+	if err := t.codec.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+
+	if err := t.l2.Put(t.key(i), buf.Bytes(), d); err != nil {
+		// Do not leave an older value behind in the first tier
+		t.l1.Remove(i)
+		return err
+	}
+
+	t.l1.Put(d, i, v)
+	return nil
+}
+
+func (t *tiered[I, T]) Remove(i I) error {
+	t.l1.Remove(i)
+	return t.l2.Delete(t.key(i))
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestTiered(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock = NewFakeClock(time.Now())
+		l1    = New[int, user](WithClock(clock))
+		l2    = NewMemoryBackend(WithClock(clock))
+		c     = NewTiered(l1, l2, nil, WithClock(clock))
+
+		alice = user{Name: "alice", Age: 30}
+	)
+
+	if _, err := c.Get(0); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+
+	if err := c.Put(time.Minute, 0, alice); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, _, err := l2.Get("0"); err != nil {
+		t.Errorf("Expected value to be written through to the backend, got %v", err)
+	}
+
+	if v, ok := l1.Get(0); !ok || v != alice {
+		t.Errorf("Expected value to be written through to the first tier, got %v, %v", v, ok)
+	}
+
+	// A cold first tier is filled from the backend with the time left
+	l1.Remove(0)
+	clock.Advance(40 * time.Second)
+
+	if v, err := c.Get(0); err != nil || v != alice {
+		t.Errorf("Expected %v, got %v, %v", alice, v, err)
+	}
+
+	if v, ok := l1.Get(0); !ok || v != alice {
+		t.Errorf("Expected first tier to be filled, got %v, %v", v, ok)
+	}
+
+	clock.Advance(30 * time.Second)
+
+	if _, ok := l1.Get(0); ok {
+		t.Error("Expected the first tier entry to expire with the backend entry")
+	}
+
+	if _, err := c.Get(0); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestTieredRemove(t *testing.T) {
+	t.Parallel()
+
+	var (
+		l1 = New[string, int]()
+		l2 = NewMemoryBackend()
+		c  = NewTiered(l1, l2, func(k string) string { return "app:" + k })
+	)
+
+	c.Put(NoExpiration, "a", 1337)
+
+	if _, _, err := l2.Get("app:a"); err != nil {
+		t.Errorf("Expected key function to be used, got %v", err)
+	}
+
+	if err := c.Remove("a"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if _, ok := l1.Get("a"); ok {
+		t.Error("Expected false, got true")
+	}
+
+	if _, err := c.Get("a"); err != ErrNotFound {
+		t.Errorf("Expected error %v, got %v", ErrNotFound, err)
+	}
+}
+
+type failingBackend struct {
+	Backend
+}
+
+var errBackend = errors.New("backend down")
+
+func (failingBackend) Put(string, []byte, time.Duration) error {
+	return errBackend
+}
+
+func TestTieredPutError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		l1 = New[int, int]()
+		c  = NewTiered(l1, failingBackend{NewMemoryBackend()}, nil)
+	)
+
+	l1.Put(time.Minute, 0, 1)
+
+	if err := c.Put(time.Minute, 0, 2); err != errBackend {
+		t.Errorf("Expected error %v, got %v", errBackend, err)
+	}
+
+	if _, ok := l1.Get(0); ok {
+		t.Error("Expected the old first tier value to be removed")
+	}
+}
+
+func TestTieredFile(t *testing.T) {
+	t.Parallel()
+
+	l2, err := NewFileBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var (
+		a = NewTiered(New[int, user](), l2, nil, WithCodec(JSONCodec))
+		b = NewTiered(New[int, user](), l2, nil, WithCodec(JSONCodec))
+
+		alice = user{Name: "alice", Age: 30}
+	)
+
+	a.Put(time.Minute, 0, alice)
+
+	// Another process sharing the backend sees the value
+	if v, err := b.Get(0); err != nil || v != alice {
+		t.Errorf("Expected %v, got %v, %v", alice, v, err)
+	}
+}